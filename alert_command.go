@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -12,15 +14,16 @@ import (
 
 // First, let's add the alert structure
 type PriceAlert struct {
-	Symbol        string
-	GeckoID       string
-	UpperTarget   float64
-	LowerTarget   float64
-	ChannelID     string
-	GuildID       string
-	CreatedAt     time.Time
-	LastAlert     time.Time
-	AlertCooldown time.Duration // Prevent spam
+	Symbol        string        `json:"symbol"`
+	GeckoID       string        `json:"gecko_id"`
+	UpperTarget   float64       `json:"upper_target,omitempty"`
+	LowerTarget   float64       `json:"lower_target,omitempty"`
+	ChannelID     string        `json:"channel_id"`
+	GuildID       string        `json:"guild_id"`
+	UserID        string        `json:"user_id,omitempty"` // Who set it, empty for alerts from before this was recorded
+	CreatedAt     time.Time     `json:"created_at"`
+	LastAlert     time.Time     `json:"last_alert"`
+	AlertCooldown time.Duration `json:"alert_cooldown"` // Prevent spam
 }
 
 type AlertBot struct {
@@ -29,12 +32,43 @@ type AlertBot struct {
 	Session    *discordgo.Session
 }
 
+const (
+	alertSetPrefix    = "alert.set"
+	alertModalPrefix  = "alert.modal"
+	alertRemovePrefix = "alert.rm"
+)
+
+// alertForm is the payload of the alert modal and the button opening it,
+// holding the targets as typed so a rejected form can be filled in again.
+type alertForm struct {
+	Coin  string `json:"c"`
+	Upper string `json:"u,omitempty"`
+	Lower string `json:"l,omitempty"`
+}
+
+// alertRef identifies one alert of a coin by its creation time.
+type alertRef struct {
+	Coin    string `json:"c"`
+	Created int64  `json:"t"`
+}
+
 var (
 	alertBot = &AlertBot{
 		Alerts: make(map[string][]PriceAlert),
 	}
 )
 
+// writeAlertsLocked stores the alerts for one coin. The caller must hold
+// alertBot.AlertMutex.
+func writeAlertsLocked(geckoID string) error {
+	return store.Update(func(tx StoreTx) error {
+		if alerts := alertBot.Alerts[geckoID]; len(alerts) > 0 {
+			return putJSON(tx, bucketAlerts, geckoID, alerts)
+		}
+		return tx.Delete(bucketAlerts, geckoID)
+	})
+}
+
 // Add function to check alerts
 func checkAlerts(price *CryptoPrice, geckoID string) {
 	alertBot.AlertMutex.Lock()
@@ -45,8 +79,20 @@ func checkAlerts(price *CryptoPrice, geckoID string) {
 		return
 	}
 
+	// Don't fire alerts on a price the providers can't agree on, or that only
+	// one of them reported in consensus mode
+	if price.Disputed {
+		log.Printf("Skipping alerts for %s: price sources disagree", geckoID)
+		return
+	}
+	if price.SingleSource != "" {
+		log.Printf("Skipping alerts for %s: only %s reported a price", geckoID, price.SingleSource)
+		return
+	}
+
 	currentTime := time.Now()
 	var remainingAlerts []PriceAlert
+	triggered := false
 
 	for _, alert := range alerts {
 		// Check if cooldown period has passed
@@ -95,6 +141,7 @@ func checkAlerts(price *CryptoPrice, geckoID string) {
 				log.Printf("Error sending alert for %s: %v", alert.Symbol, err)
 			} else {
 				alert.LastAlert = currentTime
+				triggered = true
 			}
 		}
 
@@ -102,56 +149,110 @@ func checkAlerts(price *CryptoPrice, geckoID string) {
 	}
 
 	alertBot.Alerts[geckoID] = remainingAlerts
+
+	// Keep the cooldown across restarts
+	if triggered {
+		if err := writeAlertsLocked(geckoID); err != nil {
+			log.Printf("Error saving alerts for %s: %v", geckoID, err)
+		}
+	}
 }
 
 // Add this function to handle listing alerts
 func handleListAlerts(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID, manager := i.Member.User.ID, isManager(i)
+
 	alertBot.AlertMutex.RLock()
 	defer alertBot.AlertMutex.RUnlock()
 
 	embed := &discordgo.MessageEmbed{
-		Title:  "Active Price Alerts",
-		Color:  0x00ff00,
-		Fields: []*discordgo.MessageEmbedField{},
+		Title: "Active Price Alerts",
+		Color: 0x00ff00,
 	}
 
-	if len(alertBot.Alerts) == 0 {
+	// Sorted so the pages stay in a stable order
+	ids := make([]string, 0, len(alertBot.Alerts))
+	for geckoID := range alertBot.Alerts {
+		ids = append(ids, geckoID)
+	}
+	sort.Strings(ids)
+
+	var fields []*discordgo.MessageEmbedField
+	var listed []PriceAlert
+	for _, geckoID := range ids {
+		for _, alert := range alertBot.Alerts[geckoID] {
+			if alert.GuildID != i.GuildID {
+				continue
+			}
+			var lines []string
+			if alert.UpperTarget > 0 {
+				lines = append(lines, fmt.Sprintf("Upper: $%.2f", alert.UpperTarget))
+			}
+			if alert.LowerTarget > 0 {
+				lines = append(lines, fmt.Sprintf("Lower: $%.2f", alert.LowerTarget))
+			}
+			if alert.UserID != "" {
+				lines = append(lines, fmt.Sprintf("By <@%s>", alert.UserID))
+			}
+
+			fields = append(fields, &discordgo.MessageEmbedField{
+				Name:   alert.Symbol,
+				Value:  strings.Join(lines, "\n"),
+				Inline: true,
+			})
+			listed = append(listed, alert)
+		}
+	}
+	if len(fields) == 0 {
 		embed.Description = "No active alerts"
-	} else {
-		for _, alerts := range alertBot.Alerts {
-			for _, alert := range alerts {
-				fieldValue := ""
-				if alert.UpperTarget > 0 {
-					fieldValue += fmt.Sprintf("Upper: $%.2f\n", alert.UpperTarget)
-				}
-				if alert.LowerTarget > 0 {
-					fieldValue += fmt.Sprintf("Lower: $%.2f", alert.LowerTarget)
-				}
-
-				embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-					Name:   alert.Symbol,
-					Value:  fieldValue,
-					Inline: true,
-				})
+	}
+
+	p := newPaginator(i.Member.User.ID, embedPages(splitFields(embed, fields, maxPageFields), 1))
+	p.actions = func(page int) []discordgo.MessageComponent {
+		// A menu to remove one of the alerts on this page
+		var options []discordgo.SelectMenuOption
+		for idx := page * maxPageFields; idx < len(listed) && idx < (page+1)*maxPageFields; idx++ {
+			alert := listed[idx]
+			if !canRemoveAlert(alert, userID, manager) {
+				continue
 			}
+			options = append(options, discordgo.SelectMenuOption{
+				Label: fmt.Sprintf("%s (%s)", alert.Symbol, alertTargets(alert)),
+				Value: encodePayload(alertRef{Coin: alert.GeckoID, Created: alert.CreatedAt.UnixNano()}),
+			})
+		}
+		if len(options) == 0 {
+			return nil
+		}
+		return []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.SelectMenu{
+						MenuType:    discordgo.StringSelectMenu,
+						CustomID:    componentID(alertRemovePrefix, paginationTTL, struct{}{}),
+						Placeholder: "🗑️ Remove an alert…",
+						Options:     options,
+					},
+				},
+			},
 		}
 	}
 
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{embed},
+			Embeds:     p.embeds(),
+			Components: p.components(),
 		},
 	})
 }
 
-// Add this function to handle removing alerts
+// handleRemoveAlert removes the user's alerts for a coin in this server, or
+// everyone's when a manager runs it.
 func handleRemoveAlert(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	options := i.ApplicationCommandData().Options
 	symbol := strings.ToLower(options[0].StringValue())
-
-	alertBot.AlertMutex.Lock()
-	defer alertBot.AlertMutex.Unlock()
+	userID, manager := i.Member.User.ID, isManager(i)
 
 	cryptoInfo, exists := commonCryptos[symbol]
 	if !exists {
@@ -164,16 +265,58 @@ func handleRemoveAlert(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 
-	delete(alertBot.Alerts, cryptoInfo.GeckoID)
+	alertBot.AlertMutex.Lock()
+	var kept []PriceAlert
+	removed, others := 0, 0
+	for _, alert := range alertBot.Alerts[cryptoInfo.GeckoID] {
+		switch {
+		case alert.GuildID != i.GuildID:
+			kept = append(kept, alert)
+		case canRemoveAlert(alert, userID, manager):
+			removed++
+		default:
+			kept = append(kept, alert)
+			others++
+		}
+	}
+	if removed > 0 {
+		if len(kept) == 0 {
+			delete(alertBot.Alerts, cryptoInfo.GeckoID)
+		} else {
+			alertBot.Alerts[cryptoInfo.GeckoID] = kept
+		}
+		if err := writeAlertsLocked(cryptoInfo.GeckoID); err != nil {
+			log.Printf("Error saving alerts for %s: %v", cryptoInfo.GeckoID, err)
+		}
+	}
+	alertBot.AlertMutex.Unlock()
+
+	var content string
+	switch {
+	case removed == 0 && others == 0:
+		content = "No alerts found for this cryptocurrency"
+	case removed == 0:
+		content = fmt.Sprintf("⚠️ The %s alerts in this server were set by other members, only managers can remove them", cryptoInfo.Symbol)
+	case others > 0:
+		content = fmt.Sprintf("Removed your %d %s alerts, %d set by other members were kept", removed, cryptoInfo.Symbol, others)
+	default:
+		content = fmt.Sprintf("Removed all alerts for %s", cryptoInfo.Symbol)
+	}
 
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("Removed all alerts for %s", cryptoInfo.Symbol),
+			Content: content,
 		},
 	})
 }
 
+// canRemoveAlert reports whether a member may remove an alert in their
+// server. Alerts without an owner can only be removed by managers.
+func canRemoveAlert(alert PriceAlert, userID string, manager bool) bool {
+	return manager || (alert.UserID != "" && alert.UserID == userID)
+}
+
 func handleSetAlert(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	options := i.ApplicationCommandData().Options
 	symbol := strings.ToLower(options[0].StringValue())
@@ -190,41 +333,43 @@ func handleSetAlert(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		}
 	}
 
-	// Validate targets
+	// Without targets, ask for them in a modal
 	if upperTarget == 0 && lowerTarget == 0 {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "⚠️ Please set at least one target price (upper or lower)\n" +
-					"Example: `/setalert bitcoin upper:50000 lower:40000`",
-			},
-		})
+		s.InteractionRespond(i.Interaction, alertModal(alertForm{Coin: symbol}))
 		return
 	}
 
+	embed, content := addPriceAlert(symbol, upperTarget, lowerTarget, i.ChannelID, i.GuildID, i.Member.User.ID)
+	response := &discordgo.InteractionResponseData{Content: content}
+	if embed != nil {
+		response = &discordgo.InteractionResponseData{Embeds: []*discordgo.MessageEmbed{embed}}
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: response,
+	})
+}
+
+// addPriceAlert creates an alert posting to channelID and returns the
+// confirmation embed, or a message explaining why it couldn't.
+func addPriceAlert(symbol string, upperTarget, lowerTarget float64, channelID, guildID, userID string) (*discordgo.MessageEmbed, string) {
+	// Validate targets
+	if upperTarget == 0 && lowerTarget == 0 {
+		return nil, "⚠️ Please set at least one target price (upper or lower)\n" +
+			"Example: `/setalert bitcoin upper:50000 lower:40000`"
+	}
+
 	// Get crypto info
 	cryptoInfo, exists := commonCryptos[symbol]
 	if !exists {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("❌ Cryptocurrency '%s' not found.\n"+
-					"Please use autocomplete to select a valid cryptocurrency.", symbol),
-			},
-		})
-		return
+		return nil, fmt.Sprintf("❌ Cryptocurrency '%s' not found.\n"+
+			"Please use autocomplete to select a valid cryptocurrency.", symbol)
 	}
 
 	// Get current price for reference
 	price, err := getCryptoPrice(cryptoInfo.GeckoID)
 	if err != nil {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("❌ Error fetching price for %s: %v", cryptoInfo.Symbol, err),
-			},
-		})
-		return
+		return nil, fmt.Sprintf("❌ Error fetching price for %s: %v", cryptoInfo.Symbol, err)
 	}
 
 	// Create and store alert
@@ -233,13 +378,18 @@ func handleSetAlert(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		GeckoID:       cryptoInfo.GeckoID,
 		UpperTarget:   upperTarget,
 		LowerTarget:   lowerTarget,
-		ChannelID:     i.ChannelID,
+		ChannelID:     channelID,
+		GuildID:       guildID,
+		UserID:        userID,
 		CreatedAt:     time.Now(),
 		AlertCooldown: time.Minute * 5,
 	}
 
 	alertBot.AlertMutex.Lock()
 	alertBot.Alerts[cryptoInfo.GeckoID] = append(alertBot.Alerts[cryptoInfo.GeckoID], alert)
+	if err := writeAlertsLocked(cryptoInfo.GeckoID); err != nil {
+		log.Printf("Error saving alerts for %s: %v", cryptoInfo.GeckoID, err)
+	}
 	alertBot.AlertMutex.Unlock()
 
 	// Create success response
@@ -270,7 +420,101 @@ func handleSetAlert(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			Inline: true,
 		})
 	}
+	return embed, ""
+}
+
+// setAlertButton is the "Set alert" quick action shown under a coin's price.
+func setAlertButton(geckoID string) discordgo.MessageComponent {
+	return discordgo.Button{
+		Label:    "🔔 Set alert",
+		Style:    discordgo.PrimaryButton,
+		CustomID: componentID(alertSetPrefix, quickActionTTL, alertForm{Coin: geckoID}),
+	}
+}
 
+// alertModal asks for the targets of an alert, filled in with form.
+func alertModal(form alertForm) *discordgo.InteractionResponse {
+	info := resolveCryptoInfo(form.Coin)
+	target := func(name, label, value, placeholder string) discordgo.MessageComponent {
+		return discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.TextInput{
+					CustomID:    name,
+					Label:       label,
+					Style:       discordgo.TextInputShort,
+					Placeholder: placeholder,
+					Value:       value,
+					MaxLength:   20,
+				},
+			},
+		}
+	}
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: componentID(alertModalPrefix, modalTTL, alertForm{Coin: form.Coin}),
+			Title:    fmt.Sprintf("Price alert for %s", info.Symbol),
+			Components: []discordgo.MessageComponent{
+				target("upper", "Alert when the price rises to (USD)", form.Upper, "Leave empty for no upper target"),
+				target("lower", "Alert when the price falls to (USD)", form.Lower, "Leave empty for no lower target"),
+			},
+		},
+	}
+}
+
+func handleSetAlertButton(s *discordgo.Session, i *discordgo.InteractionCreate, form alertForm) {
+	s.InteractionRespond(i.Interaction, alertModal(form))
+}
+
+// handleSetAlertModal creates the alert, or explains what's wrong with a
+// button to fix the form.
+func handleSetAlertModal(s *discordgo.Session, i *discordgo.InteractionCreate, form alertForm) {
+	values := modalValues(i)
+	form.Upper, form.Lower = values["upper"], values["lower"]
+
+	var targets [2]float64
+	var problem string
+	for idx, value := range []string{form.Upper, form.Lower} {
+		if value == "" {
+			continue
+		}
+		target, err := strconv.ParseFloat(strings.TrimPrefix(strings.ReplaceAll(value, ",", ""), "$"), 64)
+		if err != nil || target < 0 {
+			problem = fmt.Sprintf("❌ %q isn't a valid price", value)
+			break
+		}
+		targets[idx] = target
+	}
+	if problem == "" && targets[0] > 0 && targets[1] > 0 && targets[1] >= targets[0] {
+		problem = "❌ The lower target must be below the upper target"
+	}
+
+	var embed *discordgo.MessageEmbed
+	if problem == "" {
+		embed, problem = addPriceAlert(form.Coin, targets[0], targets[1], i.ChannelID, i.GuildID, i.Member.User.ID)
+	}
+	if embed == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: problem,
+				Components: []discordgo.MessageComponent{
+					discordgo.ActionsRow{
+						Components: []discordgo.MessageComponent{
+							discordgo.Button{
+								Label:    "✏️ Try again",
+								Style:    discordgo.PrimaryButton,
+								CustomID: componentID(alertSetPrefix, modalTTL, form),
+							},
+						},
+					},
+				},
+				Flags: discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
@@ -278,3 +522,77 @@ func handleSetAlert(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		},
 	})
 }
+
+// handleRemoveAlertSelect removes the alert picked from a /listalerts page.
+func handleRemoveAlertSelect(s *discordgo.Session, i *discordgo.InteractionCreate, _ struct{}) {
+	ref, err := selectedPayload[alertRef](i)
+	if err != nil {
+		return
+	}
+	userID, manager := i.Member.User.ID, isManager(i)
+
+	alertBot.AlertMutex.Lock()
+	alerts := alertBot.Alerts[ref.Coin]
+	var removed *PriceAlert
+	for idx, alert := range alerts {
+		if alert.CreatedAt.UnixNano() == ref.Created && alert.GuildID == i.GuildID {
+			if !canRemoveAlert(alert, userID, manager) {
+				alertBot.AlertMutex.Unlock()
+				respondComponentError(s, i, "⚠️ Only the member who set this alert or a manager can remove it")
+				return
+			}
+			removed = &alert
+			alertBot.Alerts[ref.Coin] = append(alerts[:idx:idx], alerts[idx+1:]...)
+			if len(alertBot.Alerts[ref.Coin]) == 0 {
+				delete(alertBot.Alerts, ref.Coin)
+			}
+			if err := writeAlertsLocked(ref.Coin); err != nil {
+				log.Printf("Error saving alerts for %s: %v", ref.Coin, err)
+			}
+			break
+		}
+	}
+	alertBot.AlertMutex.Unlock()
+
+	if removed == nil {
+		respondComponentError(s, i, "That alert was already removed")
+		return
+	}
+	respondComponentError(s, i, fmt.Sprintf("🗑️ Removed the %s alert (%s)", removed.Symbol, alertTargets(*removed)))
+}
+
+// alertTargets describes an alert's targets on one line.
+func alertTargets(alert PriceAlert) string {
+	var targets []string
+	if alert.UpperTarget > 0 {
+		targets = append(targets, fmt.Sprintf("upper $%.2f", alert.UpperTarget))
+	}
+	if alert.LowerTarget > 0 {
+		targets = append(targets, fmt.Sprintf("lower $%.2f", alert.LowerTarget))
+	}
+	return strings.Join(targets, ", ")
+}
+
+func setAlertCommandOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "crypto",
+			Description:  "Cryptocurrency name (e.g., bitcoin, ethereum)",
+			Required:     true,
+			Autocomplete: true, // Enable autocomplete
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionNumber,
+			Name:        "upper",
+			Description: "Alert when price goes above this value (e.g., 50000)",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionNumber,
+			Name:        "lower",
+			Description: "Alert when price goes below this value (e.g., 40000)",
+			Required:    false,
+		},
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	backupPrefix = "backup-"
+	backupSuffix = ".json"
+)
+
+var (
+	// Directory for /backup files, set with BACKUP_DIR
+	backupDir = "backups"
+	// Serializes backup writes so two backups never get the same name
+	backupMutex sync.Mutex
+)
+
+// backupFile is a point-in-time copy of every state bucket. Checksum is the
+// SHA-256 of the compact JSON encoding of Buckets.
+type backupFile struct {
+	SchemaVersion int                                   `json:"schema_version"`
+	CreatedAt     time.Time                             `json:"created_at"`
+	Checksum      string                                `json:"checksum"`
+	Buckets       map[string]map[string]json.RawMessage `json:"buckets"`
+}
+
+type backupInfo struct {
+	Name      string
+	CreatedAt time.Time
+	Size      int64
+}
+
+func backupChecksum(buckets map[string]map[string]json.RawMessage) (string, error) {
+	data, err := json.Marshal(buckets)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// createBackup writes the current store contents to a new file in backupDir
+// and returns its name.
+func createBackup() (string, error) {
+	backup := backupFile{
+		CreatedAt: time.Now().UTC(),
+		Buckets:   make(map[string]map[string]json.RawMessage),
+	}
+
+	err := store.View(func(tx StoreTx) error {
+		version, err := schemaVersion(tx)
+		if err != nil {
+			return err
+		}
+		backup.SchemaVersion = version
+
+		for _, bucket := range storeBuckets {
+			entries := make(map[string]json.RawMessage)
+			err := tx.ForEach(bucket, func(key string, value []byte) error {
+				entries[key] = value
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			backup.Buckets[bucket] = entries
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("error reading store: %w", err)
+	}
+
+	if backup.Checksum, err = backupChecksum(backup.Buckets); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	backupMutex.Lock()
+	defer backupMutex.Unlock()
+
+	if err := os.MkdirAll(backupDir, 0700); err != nil {
+		return "", fmt.Errorf("error creating backup directory: %w", err)
+	}
+	stamp := backup.CreatedAt.Format("20060102-150405.000")
+	name := backupPrefix + stamp + backupSuffix
+	for n := 1; ; n++ {
+		if _, err := os.Stat(filepath.Join(backupDir, name)); os.IsNotExist(err) {
+			break
+		}
+		name = fmt.Sprintf("%s%s-%d%s", backupPrefix, stamp, n, backupSuffix)
+	}
+	if err := writeFileAtomic(filepath.Join(backupDir, name), data); err != nil {
+		return "", fmt.Errorf("error writing backup: %w", err)
+	}
+	return name, nil
+}
+
+// listBackups returns the backups in backupDir, newest first.
+func listBackups() ([]backupInfo, error) {
+	entries, err := os.ReadDir(backupDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var backups []backupInfo
+	for _, entry := range entries {
+		if entry.IsDir() || !validBackupName(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupInfo{
+			Name:      entry.Name(),
+			CreatedAt: info.ModTime(),
+			Size:      info.Size(),
+		})
+	}
+
+	// Names embed the creation time, so they sort chronologically
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Name > backups[j].Name
+	})
+	return backups, nil
+}
+
+// validBackupName rejects anything that isn't a plain backup file name, so a
+// name can't point outside backupDir.
+func validBackupName(name string) bool {
+	return name == filepath.Base(name) &&
+		strings.HasPrefix(name, backupPrefix) &&
+		strings.HasSuffix(name, backupSuffix)
+}
+
+// readBackup loads a backup and checks its integrity: the checksum must match,
+// it must come from the current schema version, contain only known buckets
+// and every value must decode.
+func readBackup(name string) (*backupFile, *botState, error) {
+	if !validBackupName(name) {
+		return nil, nil, fmt.Errorf("%q is not a backup file", name)
+	}
+
+	data, err := os.ReadFile(filepath.Join(backupDir, name))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading backup: %w", err)
+	}
+
+	var backup backupFile
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return nil, nil, fmt.Errorf("backup is corrupted: %w", err)
+	}
+	if backup.Buckets == nil {
+		return nil, nil, fmt.Errorf("backup contains no data")
+	}
+
+	checksum, err := backupChecksum(backup.Buckets)
+	if err != nil {
+		return nil, nil, err
+	}
+	if checksum != backup.Checksum {
+		return nil, nil, fmt.Errorf("backup checksum doesn't match, the file was modified or truncated")
+	}
+
+	if backup.SchemaVersion != latestSchemaVersion() {
+		return nil, nil, fmt.Errorf("backup has schema version %d but this build uses %d",
+			backup.SchemaVersion, latestSchemaVersion())
+	}
+
+	known := make(map[string]bool)
+	for _, bucket := range storeBuckets {
+		known[bucket] = true
+	}
+	for bucket := range backup.Buckets {
+		if !known[bucket] {
+			return nil, nil, fmt.Errorf("backup contains unknown bucket %q", bucket)
+		}
+	}
+
+	// Decode everything up front so a bad value can't leave a half-restored store
+	staging := newMemoryStore()
+	err = staging.Update(func(tx StoreTx) error {
+		return putBackupBuckets(tx, &backup)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("backup is invalid: %w", err)
+	}
+
+	var st *botState
+	err = staging.View(func(tx StoreTx) error {
+		st, err = readState(tx)
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("backup is invalid: %w", err)
+	}
+	return &backup, st, nil
+}
+
+func putBackupBuckets(tx StoreTx, backup *backupFile) error {
+	for bucket, entries := range backup.Buckets {
+		for key, value := range entries {
+			if err := tx.Put(bucket, key, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// restoreBackup validates the backup, saves the current state to a new backup
+// and then replaces the store and in-memory state in one step. It returns the
+// name of the safety backup and the restored state.
+func restoreBackup(name string) (string, *botState, error) {
+	backup, st, err := readBackup(name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	lockState()
+	defer unlockState()
+
+	// Taken under the lock so it holds exactly the state being replaced
+	safety, err := createBackup()
+	if err != nil {
+		return "", nil, fmt.Errorf("error backing up the current state: %w", err)
+	}
+
+	err = store.Update(func(tx StoreTx) error {
+		for _, bucket := range storeBuckets {
+			// Collect first, bbolt doesn't allow deleting while iterating
+			var keys []string
+			err := tx.ForEach(bucket, func(key string, value []byte) error {
+				keys = append(keys, key)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			for _, key := range keys {
+				if err := tx.Delete(bucket, key); err != nil {
+					return err
+				}
+			}
+		}
+		return putBackupBuckets(tx, backup)
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("error restoring backup: %w", err)
+	}
+
+	st.applyLocked()
+	return safety, st, nil
+}
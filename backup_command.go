@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func handleBackupCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	name, err := createBackup()
+	if err != nil {
+		log.Printf("Error creating backup: %v", err)
+		content := fmt.Sprintf("❌ Error creating backup: %v", err)
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+		return
+	}
+	log.Printf("Backup %s created by %s", name, i.Member.User.ID)
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "💾 Backup Created",
+		Description: fmt.Sprintf("Saved `%s`. Restore it with `/restore`.", name),
+		Color:       0x00ff00,
+	}
+
+	if backups, err := listBackups(); err == nil && len(backups) > 0 {
+		const maxListed = 5
+		var lines []string
+		for idx, backup := range backups {
+			if idx == maxListed {
+				lines = append(lines, fmt.Sprintf("... and %d more", len(backups)-maxListed))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("`%s` (%.1f KB)", backup.Name, float64(backup.Size)/1024))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "Recent Backups",
+			Value: strings.Join(lines, "\n"),
+		})
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+}
+
+func handleRestoreCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var name string
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "backup" {
+			name = opt.StringValue()
+		}
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	safety, st, err := restoreBackup(name)
+	if err != nil {
+		log.Printf("Error restoring backup %s: %v", name, err)
+		content := fmt.Sprintf("❌ Backup not restored: %v", err)
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+		return
+	}
+	log.Printf("Backup %s restored by %s", name, i.Member.User.ID)
+
+	// Reconnecting bots is slow, don't hold up the response
+	go restorePriceBots(st.PriceBots)
+
+	alertCount := 0
+	for _, alerts := range st.Alerts {
+		alertCount += len(alerts)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "♻️ Backup Restored",
+		Description: fmt.Sprintf("Restored `%s`. The previous state was saved as `%s`.", name, safety),
+		Color:       0x00ff00,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "Portfolios",
+				Value:  fmt.Sprintf("%d", len(st.Portfolios)),
+				Inline: true,
+			},
+			{
+				Name:   "Groups",
+				Value:  fmt.Sprintf("%d", len(st.Funds)),
+				Inline: true,
+			},
+			{
+				Name:   "Alerts",
+				Value:  fmt.Sprintf("%d", alertCount),
+				Inline: true,
+			},
+			{
+				Name:   "Price Bots",
+				Value:  fmt.Sprintf("%d (reconnecting)", len(st.PriceBots)),
+				Inline: true,
+			},
+			{
+				Name:   "Server Settings",
+				Value:  fmt.Sprintf("%d", len(st.GuildSettings)),
+				Inline: true,
+			},
+		},
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+}
+
+func handleBackupAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, input string) {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	backups, err := listBackups()
+	if err != nil {
+		log.Printf("Error listing backups: %v", err)
+	}
+	for _, backup := range backups {
+		if len(choices) == 25 {
+			break
+		}
+		if strings.Contains(strings.ToLower(backup.Name), input) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  fmt.Sprintf("%s (%.1f KB)", backup.Name, float64(backup.Size)/1024),
+				Value: backup.Name,
+			})
+		}
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+}
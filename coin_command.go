@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const maxCoinExplorers = 3
+
+var coinPages = []string{"Market", "Price History", "About"}
+
+func handleCoinCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+
+	cryptoName := i.ApplicationCommandData().Options[0].StringValue()
+	info := resolveCryptoInfo(cryptoName)
+
+	coin, err := getCoinDetail(info.GeckoID)
+	if err != nil || coin == nil {
+		content := fmt.Sprintf("❌ Cryptocurrency '%s' not found.\n"+
+			"Please use autocomplete to select a valid cryptocurrency.", cryptoName)
+		if err != nil {
+			content = fmt.Sprintf("❌ Error fetching coin details: %v", err)
+		}
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+		return
+	}
+
+	var embeds []*discordgo.MessageEmbed
+	for page := range coinPages {
+		embeds = append(embeds, createCoinEmbed(coin, page))
+	}
+	p := newPaginator(i.Member.User.ID, embedPages(embeds, 1), coinPages...)
+	embeds, components := p.embeds(), p.components()
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &embeds,
+		Components: &components,
+	})
+}
+
+func createCoinEmbed(coin *coinDetail, page int) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("%s (%s) • %s", coin.Name, strings.ToUpper(coin.Symbol), coinPages[page]),
+		URL:   "https://www.coingecko.com/en/coins/" + url.PathEscape(coin.ID),
+		Color: getColorForChange(coin.MarketData.Change24h),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Data from CoinGecko",
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	if coin.Image.Large != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: coin.Image.Large}
+	}
+
+	switch page {
+	case 0:
+		embed.Fields = coinMarketFields(coin)
+	case 1:
+		embed.Fields = coinHistoryFields(coin)
+	case 2:
+		embed.Fields = coinAboutFields(coin)
+	}
+	return embed
+}
+
+func coinMarketFields(coin *coinDetail) []*discordgo.MessageEmbedField {
+	md := coin.MarketData
+
+	rank := "Unranked"
+	if coin.MarketCapRank > 0 {
+		rank = fmt.Sprintf("#%d", coin.MarketCapRank)
+	}
+	fdv := "n/a"
+	if value := md.FullyDilutedValue["usd"]; value > 0 {
+		fdv = formatLargeUSD(value)
+	}
+
+	circulating := formatSupply(md.CirculatingSupply)
+	if md.CirculatingSupply != nil && md.MaxSupply != nil && *md.MaxSupply > 0 {
+		circulating += fmt.Sprintf("\n%.1f%% of max", *md.CirculatingSupply / *md.MaxSupply * 100)
+	}
+	maxSupply := formatSupply(md.MaxSupply)
+	if md.MaxSupply == nil {
+		maxSupply = "∞ Uncapped"
+	}
+
+	return []*discordgo.MessageEmbedField{
+		{Name: "🏆 Rank", Value: rank, Inline: true},
+		{Name: "💰 Price", Value: fmt.Sprintf("%s\n%s", formatUSD(md.CurrentPrice["usd"]), changeIndicator(md.Change24h)), Inline: true},
+		{Name: "📊 Market Cap", Value: formatLargeUSD(md.MarketCap["usd"]), Inline: true},
+		{Name: "💎 Fully Diluted Value", Value: fdv, Inline: true},
+		{Name: "📉 24h Volume", Value: formatLargeUSD(md.TotalVolume["usd"]), Inline: true},
+		{Name: "↕️ 24h Range", Value: fmt.Sprintf("%s - %s", formatUSD(md.Low24h["usd"]), formatUSD(md.High24h["usd"])), Inline: true},
+		{Name: "🔄 Circulating Supply", Value: circulating, Inline: true},
+		{Name: "📦 Total Supply", Value: formatSupply(md.TotalSupply), Inline: true},
+		{Name: "🔒 Max Supply", Value: maxSupply, Inline: true},
+	}
+}
+
+func coinHistoryFields(coin *coinDetail) []*discordgo.MessageEmbedField {
+	md := coin.MarketData
+	return []*discordgo.MessageEmbedField{
+		{Name: "🚀 All-Time High", Value: fmt.Sprintf("%s\n%s", formatUSD(md.ATH["usd"]), formatCoinDate(md.ATHDate["usd"])), Inline: true},
+		{Name: "📉 From ATH", Value: fmt.Sprintf("%+.2f%%", md.ATHChangePercentage["usd"]), Inline: true},
+		{Name: "\u200b", Value: "\u200b", Inline: true}, // Blank field to start a new row
+		{Name: "🕳️ All-Time Low", Value: fmt.Sprintf("%s\n%s", formatUSD(md.ATL["usd"]), formatCoinDate(md.ATLDate["usd"])), Inline: true},
+		{Name: "📈 From ATL", Value: fmt.Sprintf("%+.2f%%", md.ATLChangePercentage["usd"]), Inline: true},
+		{Name: "\u200b", Value: "\u200b", Inline: true}, // Blank field to start a new row
+	}
+}
+
+func coinAboutFields(coin *coinDetail) []*discordgo.MessageEmbedField {
+	var names []string
+	for _, category := range coin.Categories {
+		if category != "" {
+			names = append(names, category)
+		}
+	}
+	categories := "None"
+	if len(names) > 0 {
+		categories = strings.Join(names, ", ")
+	}
+
+	// Native coins are listed with an empty platform and address
+	var platforms []string
+	for platform, address := range coin.Platforms {
+		if platform != "" && address != "" {
+			platforms = append(platforms, platform)
+		}
+	}
+	sort.Strings(platforms)
+	contracts := "None, this is a native coin"
+	if len(platforms) > 0 {
+		var lines []string
+		for _, platform := range platforms {
+			lines = append(lines, fmt.Sprintf("**%s**: `%s`", platform, coin.Platforms[platform]))
+		}
+		contracts = strings.Join(lines, "\n")
+	}
+
+	var links []string
+	for _, homepage := range coin.Links.Homepage {
+		if homepage != "" {
+			links = append(links, fmt.Sprintf("🏠 [Homepage](%s)", homepage))
+			break
+		}
+	}
+	explorers := 0
+	for _, site := range coin.Links.BlockchainSite {
+		if site == "" || explorers == maxCoinExplorers {
+			continue
+		}
+		name := site
+		if parsed, err := url.Parse(site); err == nil && parsed.Host != "" {
+			name = strings.TrimPrefix(parsed.Host, "www.")
+		}
+		links = append(links, fmt.Sprintf("🔍 [%s](%s)", name, site))
+		explorers++
+	}
+	if len(links) == 0 {
+		links = append(links, "None")
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "🏷️ Categories", Value: truncateFieldValue(categories)},
+		{Name: "📜 Contract Addresses", Value: truncateFieldValue(contracts)},
+		{Name: "🔗 Links", Value: strings.Join(links, "\n")},
+	}
+	if coin.GenesisDate != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "🌱 Genesis", Value: formatCoinDate(coin.GenesisDate), Inline: true})
+	}
+	if coin.HashingAlgorithm != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "⛏️ Algorithm", Value: coin.HashingAlgorithm, Inline: true})
+	}
+	return fields
+}
+
+func formatSupply(supply *float64) string {
+	if supply == nil || *supply == 0 {
+		return "n/a"
+	}
+	return formatLargeNumber(*supply)
+}
+
+// formatCoinDate formats CoinGecko's timestamps and plain dates.
+func formatCoinDate(value string) string {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format("Jan 2, 2006")
+		}
+	}
+	if value == "" {
+		return "n/a"
+	}
+	return value
+}
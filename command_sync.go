@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// devGuildID, from DEV_GUILD_ID, registers the commands to a single test
+// guild instead of globally. Guild commands update instantly while global
+// ones can take up to an hour to reach every client. Dev mode removes the
+// application's global commands so they don't show twice in the test guild,
+// so use it with a separate test application rather than the live bot.
+var devGuildID string
+
+var (
+	commandsSynced    bool
+	commandsSyncMutex sync.Mutex
+)
+
+// syncCommands makes the registered commands match the registry. It runs on
+// the first Ready, reconnects don't sync again unless that attempt failed.
+func syncCommands(s *discordgo.Session, guilds []*discordgo.Guild) {
+	commandsSyncMutex.Lock()
+	defer commandsSyncMutex.Unlock()
+	if commandsSynced {
+		return
+	}
+
+	appID := s.State.User.ID
+	desired := make([]*discordgo.ApplicationCommand, 0, len(commands))
+	for _, c := range commands {
+		desired = append(desired, c.applicationCommand())
+	}
+
+	if devGuildID != "" {
+		log.Printf("Dev mode: registering commands to guild %s only", devGuildID)
+		if err := syncCommandScope(s, appID, devGuildID, desired); err != nil {
+			log.Printf("Error syncing commands to dev guild %s: %v", devGuildID, err)
+			return
+		}
+		// Global commands would show up next to the dev guild's copies
+		if err := syncCommandScope(s, appID, "", nil); err != nil {
+			log.Printf("Error clearing global commands: %v", err)
+			return
+		}
+		commandsSynced = true
+		return
+	}
+
+	if err := syncCommandScope(s, appID, "", desired); err != nil {
+		log.Printf("Error syncing global commands: %v", err)
+		return
+	}
+	// Guild commands left from dev mode would show up next to the global ones
+	for _, guild := range guilds {
+		if err := syncCommandScope(s, appID, guild.ID, nil); err != nil {
+			log.Printf("Error clearing commands in guild %s: %v", guild.ID, err)
+		}
+	}
+	commandsSynced = true
+}
+
+// syncCommandScope overwrites the commands of a guild, or the global ones
+// when guildID is empty, if they differ from desired.
+func syncCommandScope(s *discordgo.Session, appID, guildID string, desired []*discordgo.ApplicationCommand) error {
+	scope := "global"
+	if guildID != "" {
+		scope = "guild " + guildID
+	}
+
+	existing, err := s.ApplicationCommands(appID, guildID)
+	if err != nil {
+		return fmt.Errorf("error fetching %s commands: %w", scope, err)
+	}
+
+	added, changed, removed := diffCommands(existing, desired)
+	if len(added)+len(changed)+len(removed) == 0 {
+		if len(desired) > 0 {
+			log.Printf("Commands (%s) are up to date", scope)
+		}
+		return nil
+	}
+
+	if desired == nil {
+		desired = []*discordgo.ApplicationCommand{}
+	}
+	if _, err := s.ApplicationCommandBulkOverwrite(appID, guildID, desired); err != nil {
+		return fmt.Errorf("error overwriting %s commands: %w", scope, err)
+	}
+	log.Printf("Synced commands (%s): added %s, changed %s, removed %s",
+		scope, formatCommandNames(added), formatCommandNames(changed), formatCommandNames(removed))
+	return nil
+}
+
+// diffCommands returns the names of the commands only in desired, in both
+// but different, and only in existing.
+func diffCommands(existing, desired []*discordgo.ApplicationCommand) (added, changed, removed []string) {
+	current := make(map[string]*discordgo.ApplicationCommand)
+	for _, cmd := range existing {
+		current[cmd.Name] = cmd
+	}
+
+	for _, cmd := range desired {
+		old, ok := current[cmd.Name]
+		switch {
+		case !ok:
+			added = append(added, cmd.Name)
+		case commandSignature(old) != commandSignature(cmd):
+			changed = append(changed, cmd.Name)
+		}
+		delete(current, cmd.Name)
+	}
+	for name := range current {
+		removed = append(removed, name)
+	}
+	sort.Strings(removed)
+	return added, changed, removed
+}
+
+// commandSignature is the part of a command that matters for the diff,
+// with Discord's defaults filled in so fetched and local commands compare
+// equal. IDs, versions and localizations are left out.
+func commandSignature(cmd *discordgo.ApplicationCommand) string {
+	commandType := cmd.Type
+	if commandType == 0 {
+		commandType = discordgo.ChatApplicationCommand
+	}
+	dmPermission := cmd.DMPermission == nil || *cmd.DMPermission
+	nsfw := cmd.NSFW != nil && *cmd.NSFW
+
+	data, err := json.Marshal(struct {
+		Type                     discordgo.ApplicationCommandType
+		Name                     string
+		Description              string
+		DefaultMemberPermissions *int64
+		DMPermission             bool
+		NSFW                     bool
+		Options                  []*discordgo.ApplicationCommandOption
+	}{commandType, cmd.Name, cmd.Description, cmd.DefaultMemberPermissions, dmPermission, nsfw, normalizeOptions(cmd.Options)})
+	if err != nil {
+		// Can't compare, so treat it as changed
+		log.Printf("Error encoding command %s: %v", cmd.Name, err)
+		return ""
+	}
+	return string(data)
+}
+
+func normalizeOptions(options []*discordgo.ApplicationCommandOption) []*discordgo.ApplicationCommandOption {
+	if len(options) == 0 {
+		return nil
+	}
+	normalized := make([]*discordgo.ApplicationCommandOption, len(options))
+	for idx, opt := range options {
+		copied := *opt
+		copied.NameLocalizations = nil
+		copied.DescriptionLocalizations = nil
+		copied.Options = normalizeOptions(opt.Options)
+		if len(opt.Choices) > 0 {
+			copied.Choices = make([]*discordgo.ApplicationCommandOptionChoice, len(opt.Choices))
+			for n, choice := range opt.Choices {
+				copied.Choices[n] = &discordgo.ApplicationCommandOptionChoice{Name: choice.Name, Value: choice.Value}
+			}
+		}
+		normalized[idx] = &copied
+	}
+	return normalized
+}
+
+func formatCommandNames(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return "/" + strings.Join(names, ", /")
+}
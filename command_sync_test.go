@@ -0,0 +1,123 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestDiffCommands(t *testing.T) {
+	manageServer := int64(discordgo.PermissionManageServer)
+	administrator := int64(discordgo.PermissionAdministrator)
+	dmAllowed := true
+	option := func(name string, choices ...string) *discordgo.ApplicationCommandOption {
+		opt := &discordgo.ApplicationCommandOption{Type: discordgo.ApplicationCommandOptionString, Name: name, Description: name}
+		for _, choice := range choices {
+			opt.Choices = append(opt.Choices, &discordgo.ApplicationCommandOptionChoice{Name: choice, Value: choice})
+		}
+		return opt
+	}
+	local := func(name string, permission *int64, options ...*discordgo.ApplicationCommandOption) *discordgo.ApplicationCommand {
+		return &discordgo.ApplicationCommand{Name: name, Description: name, DefaultMemberPermissions: permission, Options: options}
+	}
+	// fetched looks like a command returned by Discord, with IDs and defaults filled in
+	fetched := func(cmd *discordgo.ApplicationCommand) *discordgo.ApplicationCommand {
+		copied := *cmd
+		copied.ID = "id-" + cmd.Name
+		copied.ApplicationID = "app"
+		copied.Version = "1"
+		copied.Type = discordgo.ChatApplicationCommand
+		copied.DMPermission = &dmAllowed
+		copied.NameLocalizations = &map[discordgo.Locale]string{}
+		return &copied
+	}
+
+	tests := []struct {
+		name     string
+		existing []*discordgo.ApplicationCommand
+		desired  []*discordgo.ApplicationCommand
+		added    []string
+		changed  []string
+		removed  []string
+	}{
+		{
+			name:     "unchanged",
+			existing: []*discordgo.ApplicationCommand{fetched(local("price", nil, option("symbol"), option("period", "1d", "7d")))},
+			desired:  []*discordgo.ApplicationCommand{local("price", nil, option("symbol"), option("period", "1d", "7d"))},
+		},
+		{
+			name:     "added",
+			existing: []*discordgo.ApplicationCommand{fetched(local("price", nil))},
+			desired:  []*discordgo.ApplicationCommand{local("price", nil), local("ticker", nil)},
+			added:    []string{"ticker"},
+		},
+		{
+			name:     "removed",
+			existing: []*discordgo.ApplicationCommand{fetched(local("price", nil)), fetched(local("old", nil)), fetched(local("legacy", nil))},
+			desired:  []*discordgo.ApplicationCommand{local("price", nil)},
+			removed:  []string{"legacy", "old"},
+		},
+		{
+			name:     "option order",
+			existing: []*discordgo.ApplicationCommand{fetched(local("price", nil, option("symbol"), option("period")))},
+			desired:  []*discordgo.ApplicationCommand{local("price", nil, option("period"), option("symbol"))},
+			changed:  []string{"price"},
+		},
+		{
+			name:     "choices",
+			existing: []*discordgo.ApplicationCommand{fetched(local("price", nil, option("period", "1d", "7d")))},
+			desired:  []*discordgo.ApplicationCommand{local("price", nil, option("period", "1d", "7d", "30d"))},
+			changed:  []string{"price"},
+		},
+		{
+			name:     "permission added",
+			existing: []*discordgo.ApplicationCommand{fetched(local("providers", nil))},
+			desired:  []*discordgo.ApplicationCommand{local("providers", &manageServer)},
+			changed:  []string{"providers"},
+		},
+		{
+			name:     "permission changed",
+			existing: []*discordgo.ApplicationCommand{fetched(local("backup", &manageServer))},
+			desired:  []*discordgo.ApplicationCommand{local("backup", &administrator)},
+			changed:  []string{"backup"},
+		},
+		{
+			name:     "clearing a scope",
+			existing: []*discordgo.ApplicationCommand{fetched(local("price", nil))},
+			removed:  []string{"price"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, changed, removed := diffCommands(tt.existing, tt.desired)
+			if !reflect.DeepEqual(added, tt.added) || !reflect.DeepEqual(changed, tt.changed) || !reflect.DeepEqual(removed, tt.removed) {
+				t.Errorf("diff = added %v, changed %v, removed %v; want %v, %v, %v",
+					added, changed, removed, tt.added, tt.changed, tt.removed)
+			}
+		})
+	}
+}
+
+// Every registry command must encode and compare equal to a fresh copy of
+// itself, or every start would overwrite the commands.
+func TestCommandSignatureOfRegistryIsStable(t *testing.T) {
+	for _, c := range commands {
+		cmd := c.applicationCommand()
+		if commandSignature(cmd) == "" {
+			t.Errorf("/%s can't be encoded", cmd.Name)
+		}
+		if added, changed, removed := diffCommands([]*discordgo.ApplicationCommand{cmd}, []*discordgo.ApplicationCommand{c.applicationCommand()}); len(added)+len(changed)+len(removed) > 0 {
+			t.Errorf("/%s differs from itself", cmd.Name)
+		}
+	}
+}
+
+// Handlers assume a guild member, so no command may be offered in DMs.
+func TestCommandsAreGuildOnly(t *testing.T) {
+	for _, c := range commands {
+		if cmd := c.applicationCommand(); cmd.DMPermission == nil || *cmd.DMPermission {
+			t.Errorf("/%s can be used in DMs", cmd.Name)
+		}
+	}
+}
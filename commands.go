@@ -0,0 +1,708 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// permissionLevel is who may run a command. Discord hides commands from
+// members without the permission, and the dispatcher checks it again since
+// server admins can override command permissions.
+type permissionLevel int
+
+const (
+	permissionEveryone permissionLevel = iota
+	permissionManager                  // The guild's manager role or Manage Server, see /permissions
+	permissionManageServer
+	permissionAdministrator
+	permissionOwner // A bot owner from OWNER_IDS, for commands that affect every guild
+)
+
+// ownerIDs, from OWNER_IDS, are the users who run the bot and may use the
+// commands that read or replace every guild's data.
+var ownerIDs = make(map[string]bool)
+
+func (l permissionLevel) String() string {
+	switch l {
+	case permissionManager:
+		return "Manager"
+	case permissionManageServer:
+		return "Manage Server"
+	case permissionAdministrator:
+		return "Administrator"
+	case permissionOwner:
+		return "Bot Owner"
+	}
+	return "Everyone"
+}
+
+// requirement describes the level in a permission error.
+func (l permissionLevel) requirement() string {
+	switch l {
+	case permissionManager:
+		return "the manager role or the Manage Server permission"
+	case permissionOwner:
+		return "to be one of the bot's owners"
+	}
+	return fmt.Sprintf("the %s permission", l)
+}
+
+// defaultMemberPermissions returns the permission Discord requires before
+// showing the command, nil for everyone. The manager role differs per guild,
+// so manager commands are shown to everyone and only checked when run. Owner
+// commands are shown to administrators and checked against OWNER_IDS.
+func (l permissionLevel) defaultMemberPermissions() *int64 {
+	var permission int64
+	switch l {
+	case permissionManageServer:
+		permission = discordgo.PermissionManageServer
+	case permissionAdministrator, permissionOwner:
+		permission = discordgo.PermissionAdministrator
+	default:
+		return nil
+	}
+	return &permission
+}
+
+func (l permissionLevel) allows(i *discordgo.InteractionCreate) bool {
+	switch l {
+	case permissionManager:
+		return isManager(i)
+	case permissionOwner:
+		return i.Member != nil && ownerIDs[i.Member.User.ID]
+	}
+	permission := l.defaultMemberPermissions()
+	if permission == nil {
+		return true
+	}
+	if i.Member == nil {
+		return false
+	}
+	// Administrators have every permission
+	return i.Member.Permissions&(*permission|discordgo.PermissionAdministrator) != 0
+}
+
+// autocompleteProvider answers an autocomplete request with choices matching input.
+type autocompleteProvider func(s *discordgo.Session, i *discordgo.InteractionCreate, input string)
+
+// command is a slash command with everything needed to register, dispatch and
+// document it.
+type command struct {
+	schema     discordgo.ApplicationCommand
+	handler    func(s *discordgo.Session, i *discordgo.InteractionCreate)
+	permission permissionLevel
+
+	// autocomplete maps option names, in any subcommand, to their provider
+	autocomplete map[string]autocompleteProvider
+
+	// category groups the command in /help, help describes it with examples.
+	// Commands without help text are documented by their description.
+	category string
+	help     string
+}
+
+// Categories in the order /help shows them
+var commandCategories = []string{"📈 Market", "🔔 Alerts", "💼 Portfolio", "🤖 Price Bots", "⚙️ General"}
+
+var (
+	commands       []*command
+	commandsByName = make(map[string]*command)
+)
+
+// autocompleteOptions uses provider for every option in names.
+func autocompleteOptions(provider autocompleteProvider, names ...string) map[string]autocompleteProvider {
+	providers := make(map[string]autocompleteProvider)
+	for _, name := range names {
+		providers[name] = provider
+	}
+	return providers
+}
+
+func init() {
+	coinNames := make([]string, maxCompareCoins)
+	for n := range coinNames {
+		coinNames[n] = fmt.Sprintf("coin%d", n+1)
+	}
+
+	commands = []*command{
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "price",
+				Description: "Get cryptocurrency price information",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "crypto",
+						Description:  "Cryptocurrency name (e.g., bitcoin, ethereum)",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			handler:      handlePriceCommand,
+			autocomplete: autocompleteOptions(handleCryptoAutocomplete, "crypto"),
+			category:     "📈 Market",
+			help:         "Current price, 24h change, market cap and sentiment\nExample: `/price bitcoin`",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "coin",
+				Description: "Fundamentals, supply, all-time high and links for a cryptocurrency",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "crypto",
+						Description:  "Cryptocurrency name (e.g., bitcoin, ethereum)",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			handler:      handleCoinCommand,
+			autocomplete: autocompleteOptions(handleCryptoAutocomplete, "crypto"),
+			category:     "📈 Market",
+			help:         "Supply, all-time high and low, contract addresses and links\nExample: `/coin ethereum`",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "compare",
+				Description: "Compare 2-5 cryptocurrencies side by side",
+				Options:     compareCommandOptions(),
+			},
+			handler:      handleCompareCommand,
+			autocomplete: autocompleteOptions(handleCryptoAutocomplete, coinNames...),
+			category:     "📈 Market",
+			help:         "Compare 2-5 coins side by side with a normalized price chart\nExample: `/compare bitcoin ethereum solana days:90`",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "movers",
+				Description: "Top gainers and losers over 1h, 24h or 7d",
+				Options:     moversCommandOptions(),
+			},
+			handler:  handleMoversCommand,
+			category: "📈 Market",
+			help:     "Top gainers and losers among the 100 largest coins\nExample: `/movers period:7d`",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "market",
+				Description: "Total market cap, volume and BTC/ETH dominance",
+			},
+			handler:  handleMarketCommand,
+			category: "📈 Market",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "watch",
+				Description: "Manage your watchlist or the server's",
+				Options:     watchCommandOptions(),
+			},
+			handler:      handleWatchCommand,
+			autocomplete: autocompleteOptions(handleCryptoAutocomplete, "crypto"),
+			category:     "📈 Market",
+			help: `Keep an eye on the coins you check most
+• /watch add bitcoin - add a coin to your watchlist
+• /watch add solana scope:server - add to the server watchlist (managers)`,
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "watchlist",
+				Description: "Prices, 24h change and 7 day trend for a watchlist",
+				Options:     watchlistCommandOptions(),
+			},
+			handler:  handleWatchlistCommand,
+			category: "📈 Market",
+			help:     "Prices, 24h change and a 7 day sparkline in one embed\nExample: `/watchlist scope:server`",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "feargreed",
+				Description: "Crypto Fear & Greed index",
+				Options:     fearGreedCommandOptions(),
+			},
+			handler:  handleFearGreedCommand,
+			category: "📈 Market",
+			help: `Crypto Fear & Greed index from alternative.me
+• /feargreed now - current value with 30-day history
+• /feargreed autopost channel:#market - post the index daily (Manager)
+• /feargreed alert channel:#market fear:20 greed:80 - alert on extreme readings (Manager)`,
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "ticker",
+				Description: "Live multi-coin ticker message",
+				Options:     tickerCommandOptions(),
+			},
+			handler:    handleTickerCommand,
+			permission: permissionManageServer,
+			category:   "📈 Market",
+			help: `A live price board that edits itself in place
+• /ticker setup channel:#prices coins:btc,eth,sol - post the ticker
+• /ticker stop - remove it`,
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "digest",
+				Description: "Scheduled daily or weekly market digest",
+				Options:     digestCommandOptions(),
+			},
+			handler:  handleDigestCommand,
+			category: "📈 Market",
+			help: `Scheduled market summary with top movers, watchlist, group P/L and sentiment
+• /digest setup channel:#market time:08:00 timezone:Europe/Berlin - post every morning (Manage Server)
+• /digest setup channel:#market frequency:weekly weekday:Monday - weekly recap
+• /digest preview - see today's digest now`,
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "setalert",
+				Description: "Set price alert for a cryptocurrency, leave out the targets to fill in a form",
+				Options:     setAlertCommandOptions(),
+			},
+			handler:      handleSetAlert,
+			autocomplete: autocompleteOptions(handleCryptoAutocomplete, "crypto"),
+			category:     "🔔 Alerts",
+			help: `Alert this channel when a price crosses a target
+• /setalert bitcoin upper:50000 lower:40000
+• /setalert solana lower:20
+• /setalert ethereum - enter the targets in a form`,
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "removealert",
+				Description: "Remove price alert for a cryptocurrency",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "crypto",
+						Description:  "Cryptocurrency to remove alert for",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			handler:      handleRemoveAlert,
+			autocomplete: autocompleteOptions(handleCryptoAutocomplete, "crypto"),
+			category:     "🔔 Alerts",
+			help:         "Remove your price alerts for a cryptocurrency, managers remove everyone's\nExample: `/removealert bitcoin`",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "listalerts",
+				Description: "List all active price alerts",
+			},
+			handler:  handleListAlerts,
+			category: "🔔 Alerts",
+			help:     "Show all active price alerts, with a menu to remove them",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "setinvest",
+				Description: "Set an investment, leave out the details to fill in a form",
+				Options:     setInvestCommandOptions(),
+			},
+			handler: handleSetInvestCommand,
+			autocomplete: map[string]autocompleteProvider{
+				"symbol": handleCryptoAutocomplete,
+				"group":  handleFundAutocomplete,
+			},
+			category: "💼 Portfolio",
+			help: `Record a purchase for yourself or a group you administer
+• /setinvest bitcoin amount:0.5 price:60000 fee:3
+• /setinvest - fill in a form and pick who took part in a group buy`,
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "sellinvest",
+				Description: "Record a sale of a personal investment",
+				Options:     sellInvestCommandOptions(),
+			},
+			handler: handleSellInvestCommand,
+			autocomplete: map[string]autocompleteProvider{
+				"symbol": handleCryptoAutocomplete,
+				"group":  handleFundAutocomplete,
+			},
+			category: "💼 Portfolio",
+			help:     "Record a partial or full sale, use `group` to sell from a group you administer\nExample: `/sellinvest bitcoin amount:0.5 price:65000 fee:3`",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "removeinvest",
+				Description: "Remove an investment",
+				Options:     removeInvestCommandOptions(),
+			},
+			handler: handleRemoveInvestCommand,
+			autocomplete: map[string]autocompleteProvider{
+				"crypto": handleCryptoAutocomplete,
+				"group":  handleFundAutocomplete,
+			},
+			category: "💼 Portfolio",
+			help:     "Delete a holding and its history, for a recording mistake rather than a sale\nExample: `/removeinvest bitcoin`",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "assets",
+				Description: "Check all assets value",
+				Options:     assetsCommandOptions(),
+			},
+			handler:  handleAssetsCommand,
+			category: "💼 Portfolio",
+			help:     "Value and profit of your holdings and your groups' funds\nExample: `/assets view:allocation`",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "transactions",
+				Description: "Show your investment transaction history",
+				Options:     transactionsCommandOptions(),
+			},
+			handler:      handleTransactionsCommand,
+			autocomplete: autocompleteOptions(handleCryptoAutocomplete, "symbol"),
+			category:     "💼 Portfolio",
+			help:         "Show your buy and sell history\nExample: `/transactions symbol:bitcoin`",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "pnl",
+				Description: "Show realized profit and loss",
+				Options:     pnlCommandOptions(),
+			},
+			handler:      handlePnLCommand,
+			autocomplete: autocompleteOptions(handleCryptoAutocomplete, "symbol"),
+			category:     "💼 Portfolio",
+			help:         "Realized P/L with a per-lot breakdown using FIFO, LIFO or weighted average cost\nExample: `/pnl period:year method:fifo`",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "performance",
+				Description: "Chart your portfolio's value over time",
+				Options:     performanceCommandOptions(),
+			},
+			handler:      handlePerformanceCommand,
+			autocomplete: autocompleteOptions(handleFundAutocomplete, "group"),
+			category:     "💼 Portfolio",
+			help:         "Chart portfolio value against cost basis with time-weighted return, max drawdown and a comparison against holding BTC\nExample: `/performance days:90`",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "rebalance",
+				Description: "Target allocations and rebalancing",
+				Options:     rebalanceCommandOptions(),
+			},
+			handler:      handleRebalanceCommand,
+			autocomplete: autocompleteOptions(handleCryptoAutocomplete, "symbol"),
+			category:     "💼 Portfolio",
+			help: `Keep your portfolio at target weights
+• /rebalance target bitcoin percent:60 - set a coin's target share
+• /rebalance plan - list the buys and sells to get back on target`,
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "portfolio",
+				Description: "Export or import your portfolio",
+				Options:     portfolioCommandOptions(),
+			},
+			handler:  handlePortfolioCommand,
+			category: "💼 Portfolio",
+			help: `Back up or bulk-load your personal portfolio
+• /portfolio export format:csv - download holdings and transactions
+• /portfolio import file:trades.csv dry_run:true - preview an import
+Imports accept the bot's own CSV/JSON exports and Binance or Coinbase trade history`,
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "group",
+				Description: "Manage investment groups",
+				Options:     groupCommandOptions(),
+			},
+			handler:      handleGroupCommand,
+			autocomplete: autocompleteOptions(handleFundAutocomplete, "group"),
+			category:     "💼 Portfolio",
+			help: `Named groups for collective investing
+• /group create name:whales - create a group
+• /group add group:whales member:@alice - add a member or a whole role
+• /group admin group:whales member:@alice - let a member record trades
+• /group claim group:Fund 1 - move a group from before servers were tracked into this server
+Managers can add, remove and promote members in any group`,
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "add",
+				Description: "Add a new price display bot",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "crypto",
+						Description:  "Cryptocurrency to track (e.g., bitcoin)",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			handler:      handleAddCommand,
+			permission:   permissionManager,
+			autocomplete: autocompleteOptions(handleCryptoAutocomplete, "crypto"),
+			category:     "🤖 Price Bots",
+			help:         "Add a bot showing a coin's price as its nickname\nExample: `/add bitcoin`",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "remove",
+				Description: "Remove a price display bot",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "crypto",
+						Description: "Cryptocurrency to stop tracking",
+						Required:    true,
+					},
+				},
+			},
+			handler:    handleRemoveCommand,
+			permission: permissionManager,
+			category:   "🤖 Price Bots",
+			help:       "Remove a price display bot\nExample: `/remove bitcoin`",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "invite",
+				Description: "Get invite links for available price bots",
+			},
+			handler:  handleInviteCommand,
+			category: "🤖 Price Bots",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "restart-bot",
+				Description: "Restart this server's price bots",
+			},
+			handler:    handleRestartCommand,
+			permission: permissionManager,
+			category:   "🤖 Price Bots",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "clear-bot",
+				Description: "Remove all of this server's price bots",
+			},
+			handler:    handleClearCommand,
+			permission: permissionManager,
+			category:   "🤖 Price Bots",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "help",
+				Description: "Show available commands",
+			},
+			handler:  handleHelpCommand,
+			category: "⚙️ General",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "providers",
+				Description: "Show price provider health and circuit breaker state",
+			},
+			handler:    handleProvidersCommand,
+			permission: permissionManageServer,
+			category:   "⚙️ General",
+			help:       "Latency, error rate and circuit breaker state of each price provider, for server managers (Manage Server)",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "permissions",
+				Description: "Choose the role that manages price bots, alerts, groups and the server watchlist",
+				Options:     permissionsCommandOptions(),
+			},
+			handler:    handlePermissionsCommand,
+			permission: permissionManageServer,
+			category:   "⚙️ General",
+			help: `Let a role manage price bots, other members' alerts, the members of every group and the server watchlist
+• /permissions manager role:@mods - set the manager role
+• /permissions reset - leave it to Manage Server only`,
+		},
+		// Backups cover every guild, so they are limited to the bot's owners
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "backup",
+				Description: "Save a backup of all bot state",
+			},
+			handler:    handleBackupCommand,
+			permission: permissionOwner,
+			category:   "⚙️ General",
+			help:       "Save every server's alerts, portfolios, groups and settings, for the bot's owners listed in OWNER_IDS",
+		},
+		{
+			schema: discordgo.ApplicationCommand{
+				Name:        "restore",
+				Description: "Replace all bot state with a backup",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "backup",
+						Description:  "Backup file to restore",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			handler:      handleRestoreCommand,
+			permission:   permissionOwner,
+			autocomplete: autocompleteOptions(handleBackupAutocomplete, "backup"),
+			category:     "⚙️ General",
+			help:         "Replace every server's data with a backup, saving the current state first. Only the bot's owners listed in OWNER_IDS can restore",
+		},
+	}
+
+	for _, c := range commands {
+		if _, ok := commandsByName[c.schema.Name]; ok {
+			log.Fatalf("Command /%s is defined twice", c.schema.Name)
+		}
+		commandsByName[c.schema.Name] = c
+	}
+}
+
+// applicationCommand returns the schema to register with Discord. Every
+// handler works on a guild member, so commands aren't offered in DMs.
+func (c *command) applicationCommand() *discordgo.ApplicationCommand {
+	cmd := c.schema
+	cmd.DefaultMemberPermissions = c.permission.defaultMemberPermissions()
+	dmPermission := false
+	cmd.DMPermission = &dmPermission
+	return &cmd
+}
+
+// usage lists the subcommands or options of the command, <required> and [optional].
+func (c *command) usage() string {
+	parts := []string{"/" + c.schema.Name}
+	var subcommands []string
+	for _, opt := range c.schema.Options {
+		switch {
+		case opt.Type == discordgo.ApplicationCommandOptionSubCommand,
+			opt.Type == discordgo.ApplicationCommandOptionSubCommandGroup:
+			subcommands = append(subcommands, opt.Name)
+		case opt.Required:
+			parts = append(parts, "<"+opt.Name+">")
+		default:
+			parts = append(parts, "["+opt.Name+"]")
+		}
+	}
+	if len(subcommands) > 0 {
+		parts = append(parts, "["+strings.Join(subcommands, "|")+"]")
+	}
+	return strings.Join(parts, " ")
+}
+
+func handleSlashCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	name := i.ApplicationCommandData().Name
+	c, ok := commandsByName[name]
+	if !ok {
+		log.Printf("Ignoring unknown command /%s", name)
+		return
+	}
+
+	// Commands registered before DMs were disabled can still be run there
+	if i.Member == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("⚠️ /%s only works in a server", name),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if !c.permission.allows(i) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("⚠️ You need %s to use /%s", c.permission.requirement(), name),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+	c.handler(s, i)
+}
+
+func handleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	c, ok := commandsByName[i.ApplicationCommandData().Name]
+	if !ok {
+		return
+	}
+
+	// Complete whichever option the user is typing in
+	options := i.ApplicationCommandData().Options
+	for len(options) > 0 && (options[0].Type == discordgo.ApplicationCommandOptionSubCommand ||
+		options[0].Type == discordgo.ApplicationCommandOptionSubCommandGroup) {
+		options = options[0].Options
+	}
+	if len(options) == 0 {
+		return
+	}
+	focused := options[0]
+	for _, opt := range options {
+		if opt.Focused {
+			focused = opt
+			break
+		}
+	}
+
+	provider, ok := c.autocomplete[focused.Name]
+	if !ok {
+		return
+	}
+	if !c.permission.allows(i) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+			Data: &discordgo.InteractionResponseData{
+				Choices: []*discordgo.ApplicationCommandOptionChoice{},
+			},
+		})
+		return
+	}
+	provider(s, i, strings.ToLower(focused.StringValue()))
+}
+
+func handleHelpCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var embeds []*discordgo.MessageEmbed
+	for _, category := range commandCategories {
+		embed := &discordgo.MessageEmbed{
+			Title: "🤖 Bot Commands • " + category,
+			Color: 0x00ff00,
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: "<required> [optional] • Type a crypto name to see suggestions",
+			},
+		}
+		for _, c := range commands {
+			if c.category != category {
+				continue
+			}
+			name := c.usage()
+			if c.permission != permissionEveryone {
+				name += fmt.Sprintf(" • 🔒 %s", c.permission)
+			}
+			value := c.help
+			if value == "" {
+				value = c.schema.Description
+			}
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:  name,
+				Value: value,
+			})
+		}
+		embeds = append(embeds, embed)
+	}
+
+	p := newPaginator(i.Member.User.ID, embedPages(embeds, 1), commandCategories...)
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     p.embeds(),
+			Components: p.components(),
+		},
+	})
+}
@@ -32,7 +32,7 @@ func handlePriceCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 
-	sentiment, err := getSantimentDataForGreedFear("bitcoin")
+	sentiment, err := getMarketSentiment(cryptoInfo.GeckoID)
 	if err != nil {
 		sentiment = "Data not available"
 	}
@@ -72,65 +72,30 @@ func handlePriceCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		},
 	}
 
-	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-		Embeds: &[]*discordgo.MessageEmbed{embed},
-	})
-}
-
-func handleHelpCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	embed := &discordgo.MessageEmbed{
-		Title: "🤖 Bot Commands",
-		Color: 0x00ff00,
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:  "/price [crypto]",
-				Value: "Get current price information\nExample: `/price bitcoin`",
-			},
-			{
-				Name:  "/add [crypto]",
-				Value: "Add a new price display bot\nExample: `/add bitcoin`",
-			},
-			{
-				Name:  "/remove [crypto]",
-				Value: "Remove a price display bot\nExample: `/remove bitcoin`",
-			},
-			{
-				Name:  "/help",
-				Value: "Show this help message",
-			},
-			{
-				Name: "/setalert [crypto] [upper] [lower]",
-				Value: `Set price alerts for a cryptocurrency
-Example: 
-• /setalert bitcoin upper:50000 lower:40000
-• /setalert ethereum upper:3000
-• /setalert solana lower:20
+	if price.Disputed {
+		embed.Color = 0xffa500
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "⚠️ Price Warning",
+			Value: fmt.Sprintf("Price sources disagree by more than %.1f%%. Showing the median of %d sources.", priceDeviationThreshold, price.Sources),
+		})
+	}
+	if price.SingleSource != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "ℹ️ Single Source",
+			Value: fmt.Sprintf("Only %s answered, so this price couldn't be checked against other sources.", price.SingleSource),
+		})
+	}
 
-How to use:
-1. Type /setalert and start typing crypto name
-2. Select from autocomplete suggestions
-3. Set upper price target (optional)
-4. Set lower price target (optional)
-Must set at least one target (upper or lower)`,
-			},
-			{
-				Name:  "/removealert [crypto]",
-				Value: "Remove price alerts for a cryptocurrency\nExample: /removealert bitcoin",
-			},
-			{
-				Name:  "/listalerts",
-				Value: "Show all your active price alerts",
+	edit := &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	}
+	// Alerts are only checked for the coins in commonCryptos
+	if exists {
+		edit.Components = &[]discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{setAlertButton(cryptoInfo.GeckoID)},
 			},
-		},
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Type crypto name to see suggestions",
-		},
+		}
 	}
-
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{embed},
-		},
-	})
+	s.InteractionResponseEdit(i.Interaction, edit)
 }
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const maxCompareCoins = 5
+
+type coinComparison struct {
+	Info    CryptoInfo
+	Price   *CryptoPrice
+	History *PriceHistory
+}
+
+func handleCompareCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+
+	days := "30"
+	var coins []CryptoInfo
+	seen := make(map[string]bool)
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "days" {
+			days = opt.StringValue()
+			continue
+		}
+		info := resolveCryptoInfo(opt.StringValue())
+		if !seen[info.GeckoID] {
+			seen[info.GeckoID] = true
+			coins = append(coins, info)
+		}
+	}
+
+	if len(coins) < 2 {
+		content := "⚠️ Please choose at least two different coins to compare"
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+		return
+	}
+
+	var comparisons []coinComparison
+	for _, info := range coins {
+		price, err := getCryptoPrice(info.GeckoID)
+		if err != nil {
+			content := fmt.Sprintf("Error fetching price for %s: %s", info.Symbol, err)
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: &content,
+			})
+			return
+		}
+
+		history, err := getCryptoPriceHistory(info.GeckoID, days)
+		if err != nil {
+			log.Printf("Error fetching price history for %s: %v", info.Symbol, err)
+		}
+
+		comparisons = append(comparisons, coinComparison{Info: info, Price: price, History: history})
+	}
+
+	embed := createCompareEmbed(comparisons, days)
+	edit := &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	}
+
+	chart, err := renderCompareChart(comparisons)
+	if err != nil {
+		log.Printf("Error rendering comparison chart: %v", err)
+	} else {
+		embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://compare.png"}
+		edit.Files = []*discordgo.File{{
+			Name:        "compare.png",
+			ContentType: "image/png",
+			Reader:      bytes.NewReader(chart),
+		}}
+	}
+
+	s.InteractionResponseEdit(i.Interaction, edit)
+}
+
+// normalizedHistory rescales a price history so the first point equals 100.
+func normalizedHistory(history *PriceHistory) []ChartPoint {
+	if history == nil || len(history.Prices) == 0 || history.Prices[0][1] == 0 {
+		return nil
+	}
+
+	start := history.Prices[0][1]
+	points := make([]ChartPoint, len(history.Prices))
+	for idx, p := range history.Prices {
+		points[idx] = ChartPoint{Time: p[0], Value: p[1] / start * 100}
+	}
+	return points
+}
+
+func renderCompareChart(comparisons []coinComparison) ([]byte, error) {
+	var series []ChartSeries
+	for _, c := range comparisons {
+		series = append(series, ChartSeries{
+			Name:   c.Info.Symbol,
+			Points: normalizedHistory(c.History),
+		})
+	}
+	return renderLineChart(series, 100, 800, 400)
+}
+
+func createCompareEmbed(comparisons []coinComparison, days string) *discordgo.MessageEmbed {
+	var fields []*discordgo.MessageEmbedField
+	var symbols []string
+
+	for idx, c := range comparisons {
+		symbols = append(symbols, c.Info.Symbol)
+
+		volumeRatio := "N/A"
+		if c.Price.MarketCap > 0 {
+			volumeRatio = fmt.Sprintf("%.4f", c.Price.Volume24h/c.Price.MarketCap)
+		}
+
+		performance := "N/A"
+		if points := normalizedHistory(c.History); len(points) > 0 {
+			performance = fmt.Sprintf("%+.2f%%", points[len(points)-1].Value-100)
+		}
+
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name: fmt.Sprintf("%s %s", chartLegendEmojis[idx%len(chartLegendEmojis)], c.Info.Symbol),
+			Value: fmt.Sprintf(
+				"Price: %s\n"+
+					"24h: %.2f%%\n"+
+					"MCap: %s\n"+
+					"Volume: %s\n"+
+					"Vol/MCap: %s\n"+
+					"%sd: %s",
+				formatUSD(c.Price.Price),
+				c.Price.Change24h,
+				formatLargeUSD(c.Price.MarketCap),
+				formatLargeUSD(c.Price.Volume24h),
+				volumeRatio,
+				days,
+				performance,
+			),
+			Inline: true,
+		})
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       "Compare: " + strings.Join(symbols, " vs "),
+		Description: fmt.Sprintf("Prices normalized to 100 at the start of the %s-day window", days),
+		Color:       0x00ff00,
+		Fields:      fields,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Data from CoinGecko • " + time.Now().Format("2006-01-02 15:04:05 MST"),
+		},
+	}
+}
+
+func compareCommandOptions() []*discordgo.ApplicationCommandOption {
+	var options []*discordgo.ApplicationCommandOption
+	for n := 1; n <= maxCompareCoins; n++ {
+		options = append(options, &discordgo.ApplicationCommandOption{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         fmt.Sprintf("coin%d", n),
+			Description:  fmt.Sprintf("Cryptocurrency #%d to compare", n),
+			Required:     n <= 2,
+			Autocomplete: true,
+		})
+	}
+
+	return append(options, &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "days",
+		Description: "Chart window (default 30 days)",
+		Required:    false,
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{Name: "7 days", Value: "7"},
+			{Name: "30 days", Value: "30"},
+			{Name: "90 days", Value: "90"},
+			{Name: "1 year", Value: "365"},
+		},
+	})
+}
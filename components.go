@@ -0,0 +1,215 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Custom IDs are <prefix>:<expiry>:<payload>. The expiry is a base 36 Unix
+// time, 0 for never, and the payload is the JSON of a typed struct, so keep
+// payload field names short to stay within Discord's 100 characters. Longer
+// payloads are kept in memory and the ID carries storedPayloadMarker and a
+// state ID instead, so they stop working when the bot restarts.
+const (
+	maxCustomIDLength = 100
+	quickActionTTL    = 24 * time.Hour   // Buttons on regular command replies
+	modalTTL          = 15 * time.Minute // Time to fill in and submit a modal
+
+	storedPayloadMarker = "@"
+)
+
+type storedPayload struct {
+	data    string
+	expires time.Time // Zero for never
+}
+
+var (
+	storedPayloads      = make(map[string]storedPayload)
+	storedPayloadsMutex sync.Mutex
+)
+
+type componentHandler func(s *discordgo.Session, i *discordgo.InteractionCreate, payload string)
+
+var componentRoutes = make(map[string]componentHandler)
+
+func init() {
+	registerComponent(paginationPrefix, handlePaginationComponent)
+	registerComponent(alertSetPrefix, handleSetAlertButton)
+	registerComponent(alertModalPrefix, handleSetAlertModal)
+	registerComponent(alertRemovePrefix, handleRemoveAlertSelect)
+	registerComponent(investRemovePrefix, handleRemoveInvestSelect)
+	registerComponent(assetsRefreshPrefix, handleAssetsRefresh)
+	registerComponent(investGroupPrefix, handleInvestGroupSelect)
+	registerComponent(investUsersPrefix, handleInvestUsersSelect)
+	registerComponent(investOpenPrefix, handleInvestOpen)
+	registerComponent(investModalPrefix, handleInvestModal)
+	registerComponent(investCancelPrefix, handleInvestCancel)
+}
+
+// registerComponent routes custom IDs made by componentID with prefix to
+// handle, decoding their payload into T.
+func registerComponent[T any](prefix string, handle func(s *discordgo.Session, i *discordgo.InteractionCreate, payload T)) {
+	componentRoutes[prefix] = func(s *discordgo.Session, i *discordgo.InteractionCreate, data string) {
+		payload, err := decodePayload[T](data)
+		if err != nil {
+			log.Printf("Error decoding %s component payload %q: %v", prefix, data, err)
+			return
+		}
+		handle(s, i, payload)
+	}
+}
+
+// newStateID returns a random ID for component state kept in memory, like a
+// paginator or a form in progress.
+func newStateID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// componentID builds a custom ID for the route registered under prefix. It
+// stops working after ttl, or never when ttl is 0.
+func componentID(prefix string, ttl time.Duration, payload interface{}) string {
+	var expires int64
+	if ttl > 0 {
+		expires = time.Now().Add(ttl).Unix()
+	}
+	head := prefix + ":" + strconv.FormatInt(expires, 36) + ":"
+	data := encodePayload(payload)
+	if len(head)+len(data) <= maxCustomIDLength {
+		return head + data
+	}
+
+	log.Printf("Custom ID for %s is longer than %d characters, keeping its payload in memory", prefix, maxCustomIDLength)
+	stateID := newStateID()
+	stored := storedPayload{data: data}
+	if ttl > 0 {
+		stored.expires = time.Unix(expires, 0)
+	}
+
+	storedPayloadsMutex.Lock()
+	now := time.Now()
+	for id, other := range storedPayloads {
+		if !other.expires.IsZero() && now.After(other.expires) {
+			delete(storedPayloads, id)
+		}
+	}
+	storedPayloads[stateID] = stored
+	storedPayloadsMutex.Unlock()
+
+	return head + storedPayloadMarker + stateID
+}
+
+// loadPayload returns the payload of a custom ID, looking up the ones
+// componentID kept in memory.
+func loadPayload(data string) (string, bool) {
+	stateID, stored := strings.CutPrefix(data, storedPayloadMarker)
+	if !stored {
+		return data, true
+	}
+	storedPayloadsMutex.Lock()
+	defer storedPayloadsMutex.Unlock()
+	payload, ok := storedPayloads[stateID]
+	return payload.data, ok
+}
+
+// encodePayload encodes a typed payload for a custom ID or a select option value.
+func encodePayload(payload interface{}) string {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error encoding component payload: %v", err)
+		return "{}"
+	}
+	return string(data)
+}
+
+func decodePayload[T any](data string) (T, error) {
+	var payload T
+	err := json.Unmarshal([]byte(data), &payload)
+	return payload, err
+}
+
+// selectedPayload decodes the first value picked in a select menu.
+func selectedPayload[T any](i *discordgo.InteractionCreate) (T, error) {
+	values := i.MessageComponentData().Values
+	if len(values) == 0 {
+		var zero T
+		return zero, fmt.Errorf("nothing selected")
+	}
+	return decodePayload[T](values[0])
+}
+
+// respondComponentError answers a component interaction with a private message.
+func respondComponentError(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// handleComponent dispatches button clicks, select menus and modal submissions.
+func handleComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var customID string
+	if i.Type == discordgo.InteractionModalSubmit {
+		customID = i.ModalSubmitData().CustomID
+	} else {
+		customID = i.MessageComponentData().CustomID
+	}
+
+	parts := strings.SplitN(customID, ":", 3)
+	if len(parts) != 3 {
+		log.Printf("Ignoring component with unknown custom ID %q", customID)
+		return
+	}
+	handle, ok := componentRoutes[parts[0]]
+	if !ok {
+		log.Printf("Ignoring component with unknown custom ID %q", customID)
+		return
+	}
+
+	expires, err := strconv.ParseInt(parts[1], 36, 64)
+	if err != nil {
+		log.Printf("Ignoring component with invalid expiry %q", customID)
+		return
+	}
+	if expires != 0 && time.Now().Unix() > expires {
+		respondComponentError(s, i, "⌛ This has expired, run the command again")
+		return
+	}
+
+	payload, ok := loadPayload(parts[2])
+	if !ok {
+		respondComponentError(s, i, "⌛ This has expired, run the command again")
+		return
+	}
+	handle(s, i, payload)
+}
+
+// modalValues returns the text inputs of a submitted modal by custom ID.
+func modalValues(i *discordgo.InteractionCreate) map[string]string {
+	values := make(map[string]string)
+	for _, row := range i.ModalSubmitData().Components {
+		actions, ok := row.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, component := range actions.Components {
+			if input, ok := component.(*discordgo.TextInput); ok {
+				values[input.CustomID] = strings.TrimSpace(input.Value)
+			}
+		}
+	}
+	return values
+}
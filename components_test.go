@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComponentIDPayloads(t *testing.T) {
+	type ref struct {
+		Note string `json:"n"`
+	}
+
+	tests := []struct {
+		name   string
+		note   string
+		stored bool
+	}{
+		{"fits", "short", false},
+		{"too long", strings.Repeat("x", maxCustomIDLength), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := componentID("test", time.Hour, ref{Note: tt.note})
+			if len(id) > maxCustomIDLength {
+				t.Fatalf("custom ID is %d characters", len(id))
+			}
+
+			parts := strings.SplitN(id, ":", 3)
+			if stored := strings.HasPrefix(parts[2], storedPayloadMarker); stored != tt.stored {
+				t.Errorf("stored in memory = %v, want %v", stored, tt.stored)
+			}
+			data, ok := loadPayload(parts[2])
+			if !ok {
+				t.Fatalf("payload of %q not found", id)
+			}
+			payload, err := decodePayload[ref](data)
+			if err != nil || payload.Note != tt.note {
+				t.Errorf("payload = %+v, %v; want note %q", payload, err, tt.note)
+			}
+		})
+	}
+
+	if _, ok := loadPayload(storedPayloadMarker + "missing"); ok {
+		t.Error("an unknown stored payload was found")
+	}
+}
@@ -221,3 +221,12 @@ var convertToCMCSymbolMap = map[string]string{
 	"avail":            "avail",
 	"strk":             "strk",
 }
+
+// Santiment slugs that differ from the CoinGecko ID
+var convertToSantimentSlugMap = map[string]string{
+	"binancecoin": "binance-coin",
+	"ripple":      "xrp",
+	"avalanche-2": "avalanche",
+	"near":        "near-protocol",
+	"theta-token": "theta",
+}
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
 	"net/http"
 )
 
@@ -32,3 +38,190 @@ func getCryptoPriceHistory(id string, days string) (*PriceHistory, error) {
 
 	return &data, nil
 }
+
+// Line colors used by rendered charts, matched by chartLegendEmojis
+var chartColors = []color.RGBA{
+	{R: 0x34, G: 0x98, B: 0xdb, A: 0xff}, // Blue
+	{R: 0xe6, G: 0x7e, B: 0x22, A: 0xff}, // Orange
+	{R: 0x2e, G: 0xcc, B: 0x71, A: 0xff}, // Green
+	{R: 0xe7, G: 0x4c, B: 0x3c, A: 0xff}, // Red
+	{R: 0x9b, G: 0x59, B: 0xb6, A: 0xff}, // Purple
+	{R: 0xf1, G: 0xc4, B: 0x0f, A: 0xff}, // Yellow
+	{R: 0x8d, G: 0x6e, B: 0x63, A: 0xff}, // Brown
+	{R: 0xec, G: 0xf0, B: 0xf1, A: 0xff}, // White
+}
+
+var chartLegendEmojis = []string{"🟦", "🟧", "🟩", "🟥", "🟪", "🟨", "🟫", "⬜"}
+
+var (
+	chartBackground = color.RGBA{R: 0x2f, G: 0x31, B: 0x36, A: 0xff}
+	chartGrid       = color.RGBA{R: 0x40, G: 0x44, B: 0x4b, A: 0xff}
+	chartBaseline   = color.RGBA{R: 0x99, G: 0xaa, B: 0xb5, A: 0xff}
+)
+
+type ChartPoint struct {
+	Time  float64 // Unix milliseconds, as returned by CoinGecko
+	Value float64
+}
+
+type ChartSeries struct {
+	Name   string
+	Points []ChartPoint
+}
+
+// renderLineChart draws the series on a shared time axis and returns a PNG.
+// A horizontal reference line is drawn at baseline when it's in range.
+func renderLineChart(series []ChartSeries, baseline float64, width, height int) ([]byte, error) {
+	const padding = 20
+
+	minT, maxT := math.Inf(1), math.Inf(-1)
+	minV, maxV := math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		for _, p := range s.Points {
+			minT, maxT = math.Min(minT, p.Time), math.Max(maxT, p.Time)
+			minV, maxV = math.Min(minV, p.Value), math.Max(maxV, p.Value)
+		}
+	}
+	if math.IsInf(minT, 1) {
+		return nil, fmt.Errorf("no data to chart")
+	}
+	if maxT == minT {
+		maxT = minT + 1
+	}
+	if maxV == minV {
+		maxV, minV = maxV+1, minV-1
+	}
+	// Leave some headroom above and below the lines
+	margin := (maxV - minV) * 0.05
+	minV, maxV = minV-margin, maxV+margin
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{chartBackground}, image.Point{}, draw.Src)
+
+	plotW := float64(width - 2*padding)
+	plotH := float64(height - 2*padding)
+	toPixel := func(p ChartPoint) (int, int) {
+		x := padding + (p.Time-minT)/(maxT-minT)*plotW
+		y := padding + (1-(p.Value-minV)/(maxV-minV))*plotH
+		return int(x), int(y)
+	}
+
+	for i := 0; i <= 4; i++ {
+		y := padding + int(plotH*float64(i)/4)
+		drawLine(img, padding, y, width-padding, y, chartGrid, 1)
+	}
+
+	if baseline > minV && baseline < maxV {
+		_, y := toPixel(ChartPoint{Time: minT, Value: baseline})
+		drawLine(img, padding, y, width-padding, y, chartBaseline, 1)
+	}
+
+	for idx, s := range series {
+		c := chartColors[idx%len(chartColors)]
+		for j := 1; j < len(s.Points); j++ {
+			x0, y0 := toPixel(s.Points[j-1])
+			x1, y1 := toPixel(s.Points[j])
+			drawLine(img, x0, y0, x1, y1, c, 2)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawLine draws a line of the given thickness using Bresenham's algorithm.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color, thickness int) {
+	dx := x1 - x0
+	if dx < 0 {
+		dx = -dx
+	}
+	dy := y1 - y0
+	if dy < 0 {
+		dy = -dy
+	}
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx - dy
+
+	for {
+		for tx := 0; tx < thickness; tx++ {
+			for ty := 0; ty < thickness; ty++ {
+				img.Set(x0+tx, y0+ty, c)
+			}
+		}
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 > -dy {
+			err -= dy
+			x0 += sx
+		}
+		if e2 < dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// renderDonutChart draws the values as slices of a donut, clockwise from the
+// top in chartColors order, and returns a PNG.
+func renderDonutChart(values []float64, size int) ([]byte, error) {
+	var total float64
+	for _, v := range values {
+		total += math.Max(v, 0)
+	}
+	if total <= 0 {
+		return nil, fmt.Errorf("no data to chart")
+	}
+
+	// Cumulative end angle of each slice, as a fraction of the full circle
+	ends := make([]float64, len(values))
+	var cumulative float64
+	for idx, v := range values {
+		cumulative += math.Max(v, 0) / total
+		ends[idx] = cumulative
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), &image.Uniform{chartBackground}, image.Point{}, draw.Src)
+
+	center := float64(size) / 2
+	outer := center - 10
+	inner := outer * 0.55
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)+0.5-center, float64(y)+0.5-center
+			r := math.Hypot(dx, dy)
+			if r > outer || r < inner {
+				continue
+			}
+
+			// Angle measured clockwise from 12 o'clock
+			fraction := math.Atan2(dx, -dy) / (2 * math.Pi)
+			if fraction < 0 {
+				fraction++
+			}
+			for idx, end := range ends {
+				if fraction <= end {
+					img.Set(x, y, chartColors[idx%len(chartColors)])
+					break
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
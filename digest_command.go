@@ -0,0 +1,556 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	_ "time/tzdata" // Timezones must work in containers without zoneinfo
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	digestDaily  = "daily"
+	digestWeekly = "weekly"
+
+	digestTopMovers = 3
+	// A digest missed by more than this, e.g. while the bot was down, is skipped
+	digestCatchUpWindow = 6 * time.Hour
+)
+
+// DigestSettings schedules a market summary post. Hour and Minute are in Timezone.
+type DigestSettings struct {
+	ChannelID  string       `json:"channel_id"`
+	Frequency  string       `json:"frequency"` // "daily" or "weekly"
+	Weekday    time.Weekday `json:"weekday"`   // Only used for weekly digests
+	Hour       int          `json:"hour"`
+	Minute     int          `json:"minute"`
+	Timezone   string       `json:"timezone"`
+	LastPosted time.Time    `json:"last_posted"`
+}
+
+// lastSlot returns the most recent scheduled time at or before now.
+func (d *DigestSettings) lastSlot(now time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(d.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	local := now.In(loc)
+	slot := time.Date(local.Year(), local.Month(), local.Day(), d.Hour, d.Minute, 0, 0, loc)
+	if slot.After(local) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	if d.Frequency == digestWeekly {
+		for slot.Weekday() != d.Weekday {
+			slot = slot.AddDate(0, 0, -1)
+		}
+	}
+	return slot, nil
+}
+
+// due reports whether a digest should be posted now, and for which slot.
+func (d *DigestSettings) due(now time.Time) (time.Time, bool) {
+	slot, err := d.lastSlot(now)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return slot, d.LastPosted.Before(slot) && now.Sub(slot) < digestCatchUpWindow
+}
+
+// parseDigestTime parses a 24 hour "HH:MM" time.
+func parseDigestTime(value string) (int, int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, 0, fmt.Errorf("use the 24 hour HH:MM format, e.g. 08:30")
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+func respondDigest(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func handleDigestCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return
+	}
+
+	switch options[0].Name {
+	case "setup":
+		handleDigestSetup(s, i, options[0].Options)
+	case "stop":
+		handleDigestStop(s, i)
+	case "preview":
+		handleDigestPreview(s, i)
+	}
+}
+
+func handleDigestSetup(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	if i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		respondDigest(s, i, "⚠️ You need the Manage Server permission to schedule digests")
+		return
+	}
+
+	digest := &DigestSettings{
+		Frequency:  digestDaily,
+		Weekday:    time.Monday,
+		Timezone:   "UTC",
+		LastPosted: time.Now(), // Don't post for a slot that already passed today
+	}
+	timeValue := "08:00"
+	for _, opt := range options {
+		switch opt.Name {
+		case "channel":
+			digest.ChannelID = opt.ChannelValue(s).ID
+		case "time":
+			timeValue = opt.StringValue()
+		case "timezone":
+			digest.Timezone = strings.TrimSpace(opt.StringValue())
+		case "frequency":
+			digest.Frequency = opt.StringValue()
+		case "weekday":
+			digest.Weekday = time.Weekday(opt.IntValue())
+		}
+	}
+
+	var err error
+	if digest.Hour, digest.Minute, err = parseDigestTime(timeValue); err != nil {
+		respondDigest(s, i, fmt.Sprintf("⚠️ Invalid time %q: %v", timeValue, err))
+		return
+	}
+	if _, err := time.LoadLocation(digest.Timezone); err != nil {
+		respondDigest(s, i, fmt.Sprintf("⚠️ Unknown timezone %q. Use an IANA name such as `Europe/Berlin` or `America/New_York`.", digest.Timezone))
+		return
+	}
+
+	updateGuildSettings(i.GuildID, func(settings *GuildSettings) {
+		settings.Digest = digest
+	})
+
+	when := fmt.Sprintf("every day at %02d:%02d", digest.Hour, digest.Minute)
+	if digest.Frequency == digestWeekly {
+		when = fmt.Sprintf("every %s at %02d:%02d", digest.Weekday, digest.Hour, digest.Minute)
+	}
+	respondDigest(s, i, fmt.Sprintf("✅ Market digest will be posted in <#%s> %s (%s). Use `/digest preview` to see it now.",
+		digest.ChannelID, when, digest.Timezone))
+}
+
+func handleDigestStop(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		respondDigest(s, i, "⚠️ You need the Manage Server permission to schedule digests")
+		return
+	}
+
+	stopped := false
+	updateGuildSettings(i.GuildID, func(settings *GuildSettings) {
+		stopped = settings.Digest != nil
+		settings.Digest = nil
+	})
+
+	if !stopped {
+		respondDigest(s, i, "No digest is scheduled in this server")
+		return
+	}
+	respondDigest(s, i, "✅ Scheduled digest stopped")
+}
+
+func handleDigestPreview(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	frequency := digestDaily
+	settingsMutex.RLock()
+	if settings, ok := guildSettings[i.GuildID]; ok && settings.Digest != nil {
+		frequency = settings.Digest.Frequency
+	}
+	settingsMutex.RUnlock()
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	embed, err := createDigestEmbed(i.GuildID, frequency)
+	if err != nil {
+		content := fmt.Sprintf("❌ Error building digest: %v", err)
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+		return
+	}
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+}
+
+// digestCoins lists the coins the guild follows: its watchlist, ticker, price
+// bots, alerts and group holdings. It falls back to the largest coins.
+func digestCoins(guildID string) []string {
+	var coins []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			coins = append(coins, id)
+		}
+	}
+
+	watchlistMutex.RLock()
+	for _, id := range watchlists[watchlistKey(watchlistScopeAll, guildID)] {
+		add(id)
+	}
+	watchlistMutex.RUnlock()
+
+	settingsMutex.RLock()
+	if settings, ok := guildSettings[guildID]; ok && settings.Ticker != nil {
+		for _, id := range settings.Ticker.Coins {
+			add(id)
+		}
+	}
+	settingsMutex.RUnlock()
+
+	botsMutex.RLock()
+	for symbol, bot := range priceBots {
+		if bot.GuildID == guildID {
+			add(resolveCryptoInfo(symbol).GeckoID)
+		}
+	}
+	botsMutex.RUnlock()
+
+	alertBot.AlertMutex.RLock()
+	for geckoID, alerts := range alertBot.Alerts {
+		for _, alert := range alerts {
+			if alert.GuildID == guildID {
+				add(geckoID)
+				break
+			}
+		}
+	}
+	alertBot.AlertMutex.RUnlock()
+
+	portMutex.RLock()
+	for _, f := range funds {
+		if f.inGuild(guildID) {
+			for _, h := range f.Holdings() {
+				add(h.Symbol)
+			}
+		}
+	}
+	portMutex.RUnlock()
+
+	if len(coins) == 0 {
+		return defaultTickerCoins
+	}
+	return coins
+}
+
+// marketChange is the coin's change over the digest period. Weekly changes
+// come from the 7 day sparkline when it's available.
+func marketChange(market *coinMarket, frequency string) float64 {
+	prices := market.Sparkline.Price
+	if frequency == digestWeekly && len(prices) > 1 && prices[0] > 0 {
+		return (prices[len(prices)-1] - prices[0]) / prices[0] * 100
+	}
+	return market.Change24h
+}
+
+type digestMover struct {
+	Symbol string
+	Change float64
+}
+
+func formatMovers(movers []digestMover) string {
+	if len(movers) == 0 {
+		return "None"
+	}
+	var lines []string
+	for _, m := range movers {
+		lines = append(lines, fmt.Sprintf("**%s** %+.2f%%", m.Symbol, m.Change))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func createDigestEmbed(guildID, frequency string) (*discordgo.MessageEmbed, error) {
+	coins := digestCoins(guildID)
+
+	type fundHoldings struct {
+		name     string
+		holdings []*Holding
+	}
+	var groups []fundHoldings
+	portMutex.RLock()
+	for _, f := range funds {
+		if f.inGuild(guildID) {
+			groups = append(groups, fundHoldings{name: f.Name, holdings: f.Holdings()})
+		}
+	}
+	portMutex.RUnlock()
+	sort.Slice(groups, func(a, b int) bool { return groups[a].name < groups[b].name })
+
+	markets, err := getCoinMarkets(coins)
+	if err != nil {
+		return nil, err
+	}
+
+	period := "24h"
+	title := "☀️ Daily Market Digest"
+	if frequency == digestWeekly {
+		period = "7d"
+		title = "📅 Weekly Market Digest"
+	}
+
+	var movers []digestMover
+	for _, id := range coins {
+		if market := markets[id]; market != nil {
+			movers = append(movers, digestMover{
+				Symbol: resolveCryptoInfo(id).Symbol,
+				Change: marketChange(market, frequency),
+			})
+		}
+	}
+	sort.Slice(movers, func(a, b int) bool { return movers[a].Change > movers[b].Change })
+
+	var gainers, losers []digestMover
+	for _, m := range movers {
+		if m.Change >= 0 && len(gainers) < digestTopMovers {
+			gainers = append(gainers, m)
+		}
+	}
+	for idx := len(movers) - 1; idx >= 0 && len(losers) < digestTopMovers; idx-- {
+		if movers[idx].Change < 0 {
+			losers = append(losers, movers[idx])
+		}
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: title,
+		Color: 0x3498db,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   fmt.Sprintf("🚀 Top Gainers (%s)", period),
+				Value:  formatMovers(gainers),
+				Inline: true,
+			},
+			{
+				Name:   fmt.Sprintf("📉 Top Losers (%s)", period),
+				Value:  formatMovers(losers),
+				Inline: true,
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Tracking %d coins • /digest to configure", len(movers)),
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	// Watchlist performance
+	watchlistMutex.RLock()
+	watched := append([]string{}, watchlists[watchlistKey(watchlistScopeAll, guildID)]...)
+	watchlistMutex.RUnlock()
+	if len(watched) > 0 {
+		var total float64
+		var counted int
+		for _, id := range watched {
+			if market := markets[id]; market != nil {
+				total += marketChange(market, frequency)
+				counted++
+			}
+		}
+		if counted > 0 {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:  "👀 Watchlist",
+				Value: fmt.Sprintf("%d coins, average %+.2f%% over %s", counted, total/float64(counted), period),
+			})
+		}
+	}
+
+	// Collective portfolio P/L
+	var lines []string
+	for _, g := range groups {
+		var value, cost, realized float64
+		complete := true
+		for _, h := range g.holdings {
+			market := markets[h.Symbol]
+			if market == nil {
+				complete = false
+				continue
+			}
+			value += h.Amount * market.CurrentPrice
+			cost += h.CostBasis
+			realized += h.RealizedPL
+		}
+		if len(g.holdings) == 0 {
+			continue
+		}
+
+		line := fmt.Sprintf("**%s**: %s", g.name, formatLargeUSD(value))
+		if cost > 0 {
+			pl := value - cost
+			line += fmt.Sprintf(" (%s%s, %+.2f%%)", signPrefix(pl), formatLargeUSD(abs(pl)), pl/cost*100)
+		}
+		if realized != 0 {
+			line += fmt.Sprintf(", realized %s%s", signPrefix(realized), formatLargeUSD(abs(realized)))
+		}
+		if !complete {
+			line += " ⚠️ some prices unavailable"
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "👥 Group Portfolios",
+			Value: truncateFieldValue(strings.Join(lines, "\n")),
+		})
+	}
+
+	// Sentiment
+	sentiment := "Data not available"
+	if history, err := getFearGreedHistory(1); err == nil && len(history) > 0 {
+		current := history[0]
+		sentiment = fmt.Sprintf("%s **%d** — %s", fearGreedEmoji(current.Value), current.Value, current.Classification)
+	} else if err != nil {
+		log.Printf("Error fetching Fear & Greed index for digest: %v", err)
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:  "🧭 Fear & Greed",
+		Value: sentiment,
+	})
+
+	return embed, nil
+}
+
+func signPrefix(value float64) string {
+	if value < 0 {
+		return "-"
+	}
+	return "+"
+}
+
+// runDigestScheduler posts due digests, checking every minute so the
+// configured time is met closely.
+func runDigestScheduler(s *discordgo.Session) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		postDueDigests(s)
+		<-ticker.C
+	}
+}
+
+func postDueDigests(s *discordgo.Session) {
+	type dueDigest struct {
+		guildID   string
+		channelID string
+		frequency string
+		slot      time.Time
+	}
+
+	now := time.Now()
+	var pending []dueDigest
+	settingsMutex.RLock()
+	for guildID, settings := range guildSettings {
+		if settings.Digest == nil {
+			continue
+		}
+		if slot, due := settings.Digest.due(now); due {
+			pending = append(pending, dueDigest{
+				guildID:   guildID,
+				channelID: settings.Digest.ChannelID,
+				frequency: settings.Digest.Frequency,
+				slot:      slot,
+			})
+		}
+	}
+	settingsMutex.RUnlock()
+
+	for _, d := range pending {
+		// Mark the slot first so a failing channel isn't retried every minute
+		updateGuildSettings(d.guildID, func(settings *GuildSettings) {
+			if settings.Digest != nil && settings.Digest.LastPosted.Before(d.slot) {
+				settings.Digest.LastPosted = d.slot
+			}
+		})
+
+		embed, err := createDigestEmbed(d.guildID, d.frequency)
+		if err != nil {
+			log.Printf("Error building digest for guild %s: %v", d.guildID, err)
+			continue
+		}
+		if _, err := s.ChannelMessageSendEmbed(d.channelID, embed); err != nil {
+			log.Printf("Error posting digest in guild %s: %v", d.guildID, err)
+		}
+	}
+}
+
+func digestCommandOptions() []*discordgo.ApplicationCommandOption {
+	weekdays := make([]*discordgo.ApplicationCommandOptionChoice, 0, 7)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		weekdays = append(weekdays, &discordgo.ApplicationCommandOptionChoice{Name: day.String(), Value: int(day)})
+	}
+
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "setup",
+			Description: "Schedule a market digest",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "Channel to post in",
+					Required:     true,
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "time",
+					Description: "Time of day as HH:MM, 24 hour (default 08:00)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "timezone",
+					Description: "IANA timezone such as Europe/Berlin (default UTC)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "frequency",
+					Description: "How often to post (default daily)",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Daily", Value: digestDaily},
+						{Name: "Weekly", Value: digestWeekly},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "weekday",
+					Description: "Day for weekly digests (default Monday)",
+					Required:    false,
+					Choices:     weekdays,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "stop",
+			Description: "Stop the scheduled digest",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "preview",
+			Description: "Show the digest now, only to you",
+		},
+	}
+}
@@ -0,0 +1,443 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	defaultFearThreshold  = 25
+	defaultGreedThreshold = 75
+)
+
+type FearGreedPoint struct {
+	Value          int
+	Classification string
+	Timestamp      time.Time
+}
+
+type fearGreedResponse struct {
+	Data []struct {
+		Value               string `json:"value"`
+		ValueClassification string `json:"value_classification"`
+		Timestamp           string `json:"timestamp"`
+	} `json:"data"`
+}
+
+// getFearGreedHistory returns the last `days` values of the alternative.me
+// Fear & Greed index, newest first.
+func getFearGreedHistory(days int) ([]FearGreedPoint, error) {
+	url := fmt.Sprintf("https://api.alternative.me/fng/?limit=%d", days)
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 429 {
+		return nil, fmt.Errorf("rate limit exceeded")
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("alternative.me API error: status %d", resp.StatusCode)
+	}
+
+	var fgResp fearGreedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&fgResp); err != nil {
+		return nil, err
+	}
+
+	var points []FearGreedPoint
+	for _, d := range fgResp.Data {
+		value, err := strconv.Atoi(d.Value)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing index value: %v", err)
+		}
+		ts, err := strconv.ParseInt(d.Timestamp, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing timestamp: %v", err)
+		}
+		points = append(points, FearGreedPoint{
+			Value:          value,
+			Classification: d.ValueClassification,
+			Timestamp:      time.Unix(ts, 0),
+		})
+	}
+
+	if len(points) == 0 {
+		return nil, fmt.Errorf("no Fear & Greed data available")
+	}
+	return points, nil
+}
+
+func fearGreedColor(value int) int {
+	switch {
+	case value <= 25:
+		return 0xff0000
+	case value <= 45:
+		return 0xff8c00
+	case value < 55:
+		return 0xffd700
+	case value < 75:
+		return 0x9acd32
+	default:
+		return 0x00ff00
+	}
+}
+
+func fearGreedEmoji(value int) string {
+	switch {
+	case value <= 25:
+		return "😱"
+	case value <= 45:
+		return "😟"
+	case value < 55:
+		return "😐"
+	case value < 75:
+		return "🙂"
+	default:
+		return "🤑"
+	}
+}
+
+// fearGreedZone returns "fear" or "greed" when the value is beyond the
+// alert thresholds, or "neutral" otherwise.
+func fearGreedZone(value int, alert *FearGreedAlert) string {
+	switch {
+	case value <= alert.FearThreshold:
+		return "fear"
+	case value >= alert.GreedThreshold:
+		return "greed"
+	default:
+		return "neutral"
+	}
+}
+
+func createFearGreedEmbed(history []FearGreedPoint) *discordgo.MessageEmbed {
+	current := history[0]
+
+	// Sparkline reads oldest to newest
+	values := make([]float64, len(history))
+	minValue, maxValue := current.Value, current.Value
+	for idx, p := range history {
+		values[len(history)-1-idx] = float64(p.Value)
+		if p.Value < minValue {
+			minValue = p.Value
+		}
+		if p.Value > maxValue {
+			maxValue = p.Value
+		}
+	}
+
+	fields := []*discordgo.MessageEmbedField{}
+	if len(history) > 1 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Yesterday",
+			Value:  fmt.Sprintf("%d (%s)", history[1].Value, history[1].Classification),
+			Inline: true,
+		})
+	}
+	if len(history) > 7 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Last Week",
+			Value:  fmt.Sprintf("%d (%s)", history[7].Value, history[7].Classification),
+			Inline: true,
+		})
+	}
+	fields = append(fields,
+		&discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("%dd Range", len(history)),
+			Value:  fmt.Sprintf("%d – %d", minValue, maxValue),
+			Inline: true,
+		},
+		&discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("Last %d Days", len(history)),
+			Value: fmt.Sprintf("`%s`", sparkline(values)),
+		},
+	)
+
+	return &discordgo.MessageEmbed{
+		Title:       "Crypto Fear & Greed Index",
+		Description: fmt.Sprintf("%s **%d** — %s", fearGreedEmoji(current.Value), current.Value, current.Classification),
+		Color:       fearGreedColor(current.Value),
+		Fields:      fields,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Data from alternative.me • Updated " + current.Timestamp.UTC().Format("2006-01-02 MST"),
+		},
+	}
+}
+
+func handleFearGreedCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return
+	}
+
+	// Posts and alerts are set up for the whole server
+	if options[0].Name != "now" && !isManager(i) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("⚠️ You need %s to set up Fear & Greed posts and alerts", permissionManager.requirement()),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	switch options[0].Name {
+	case "now":
+		handleFearGreedNow(s, i)
+	case "autopost":
+		handleFearGreedAutopost(s, i, options[0].Options)
+	case "alert":
+		handleFearGreedAlert(s, i, options[0].Options)
+	}
+}
+
+func handleFearGreedNow(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+
+	history, err := getFearGreedHistory(30)
+	if err != nil {
+		content := fmt.Sprintf("Error: %s", err)
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+		return
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{createFearGreedEmbed(history)},
+	})
+}
+
+func handleFearGreedAutopost(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	var channelID string
+	enabled := true
+	for _, opt := range options {
+		switch opt.Name {
+		case "channel":
+			channelID = opt.ChannelValue(s).ID
+		case "enabled":
+			enabled = opt.BoolValue()
+		}
+	}
+
+	updateGuildSettings(i.GuildID, func(settings *GuildSettings) {
+		if enabled {
+			settings.FearGreedChannelID = channelID
+			settings.FearGreedLastPosted = time.Time{}
+		} else {
+			settings.FearGreedChannelID = ""
+		}
+	})
+
+	content := "✅ Daily Fear & Greed posts disabled"
+	if enabled {
+		content = fmt.Sprintf("✅ Fear & Greed index will be posted daily in <#%s>", channelID)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+		},
+	})
+}
+
+func handleFearGreedAlert(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	alert := &FearGreedAlert{
+		FearThreshold:  defaultFearThreshold,
+		GreedThreshold: defaultGreedThreshold,
+	}
+	enabled := true
+	for _, opt := range options {
+		switch opt.Name {
+		case "channel":
+			alert.ChannelID = opt.ChannelValue(s).ID
+		case "fear":
+			alert.FearThreshold = int(opt.IntValue())
+		case "greed":
+			alert.GreedThreshold = int(opt.IntValue())
+		case "enabled":
+			enabled = opt.BoolValue()
+		}
+	}
+
+	if enabled && alert.FearThreshold >= alert.GreedThreshold {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "⚠️ The fear threshold must be lower than the greed threshold",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	updateGuildSettings(i.GuildID, func(settings *GuildSettings) {
+		if enabled {
+			settings.FearGreedAlert = alert
+		} else {
+			settings.FearGreedAlert = nil
+		}
+	})
+
+	content := "✅ Fear & Greed alerts disabled"
+	if enabled {
+		content = fmt.Sprintf("✅ Alerting in <#%s> when the index drops to %d or below, or rises to %d or above",
+			alert.ChannelID, alert.FearThreshold, alert.GreedThreshold)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+		},
+	})
+}
+
+// runFearGreedScheduler checks the index every hour, posting the daily
+// summary and extreme-zone alerts to configured channels.
+func runFearGreedScheduler(s *discordgo.Session) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		checkFearGreedSchedules(s)
+		<-ticker.C
+	}
+}
+
+func checkFearGreedSchedules(s *discordgo.Session) {
+	type pendingPost struct {
+		channelID string
+		content   string
+	}
+
+	settingsMutex.RLock()
+	configured := len(guildSettings) > 0
+	settingsMutex.RUnlock()
+	if !configured {
+		return
+	}
+
+	history, err := getFearGreedHistory(30)
+	if err != nil {
+		log.Printf("Error fetching Fear & Greed index: %v", err)
+		return
+	}
+	current := history[0]
+
+	var posts []pendingPost
+	forEachGuildSettings(func(settings *GuildSettings) {
+		if settings.FearGreedChannelID != "" && current.Timestamp.After(settings.FearGreedLastPosted) {
+			posts = append(posts, pendingPost{channelID: settings.FearGreedChannelID})
+			settings.FearGreedLastPosted = current.Timestamp
+		}
+
+		if alert := settings.FearGreedAlert; alert != nil {
+			zone := fearGreedZone(current.Value, alert)
+			if zone != alert.LastZone && zone != "neutral" {
+				zoneName := "Fear"
+				if zone == "greed" {
+					zoneName = "Greed"
+				}
+				posts = append(posts, pendingPost{
+					channelID: alert.ChannelID,
+					content: fmt.Sprintf("🚨 Fear & Greed index has entered **Extreme %s** territory: %d (%s)",
+						zoneName, current.Value, current.Classification),
+				})
+			}
+			alert.LastZone = zone
+		}
+	})
+
+	embed := createFearGreedEmbed(history)
+	for _, post := range posts {
+		msg := &discordgo.MessageSend{
+			Content: post.content,
+			Embeds:  []*discordgo.MessageEmbed{embed},
+		}
+		if _, err := s.ChannelMessageSendComplex(post.channelID, msg); err != nil {
+			log.Printf("Error posting Fear & Greed index to %s: %v", post.channelID, err)
+		}
+	}
+}
+
+func fearGreedCommandOptions() []*discordgo.ApplicationCommandOption {
+	minThreshold := 0.0
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "now",
+			Description: "Show the current index and its 30-day history",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "autopost",
+			Description: "Post the index daily to a channel",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "Channel to post in",
+					Required:     true,
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "enabled",
+					Description: "Set to false to stop daily posts",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "alert",
+			Description: "Alert when the index enters extreme fear or greed",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "Channel to alert in",
+					Required:     true,
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "fear",
+					Description: "Alert at or below this value (default 25)",
+					Required:    false,
+					MinValue:    &minThreshold,
+					MaxValue:    100,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "greed",
+					Description: "Alert at or above this value (default 75)",
+					Required:    false,
+					MinValue:    &minThreshold,
+					MaxValue:    100,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "enabled",
+					Description: "Set to false to stop alerts",
+					Required:    false,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,414 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Fund is a group-owned collective portfolio, managed with /group. Members own
+// each of the fund's holdings in proportion to how much they contributed to
+// the purchases of that coin.
+type Fund struct {
+	ID           string         `json:"id"`
+	Name         string         `json:"name"`
+	GuildID      string         `json:"guild_id,omitempty"`
+	CreatedBy    string         `json:"created_by"`
+	CreatedAt    time.Time      `json:"created_at"`
+	Members      []string       `json:"members"`
+	Admins       []string       `json:"admins,omitempty"` // The creator is always an admin
+	Transactions []*Transaction `json:"transactions"`
+}
+
+// MemberPosition is one member's pro-rata slice of a fund.
+type MemberPosition struct {
+	UserID       string
+	Contribution float64
+	Shares       map[string]float64 // Fraction of each coin owned, 0-1, by CoinGecko ID
+}
+
+// fundSnapshot is a copy of a fund's state that can be rendered without holding portMutex.
+type fundSnapshot struct {
+	Name      string
+	CreatedBy string
+	Holdings  []*Holding
+	Positions []MemberPosition
+}
+
+// snapshot copies the fund's state; the caller must hold portMutex.
+func (f *Fund) snapshot() fundSnapshot {
+	return fundSnapshot{
+		Name:      f.Name,
+		CreatedBy: f.CreatedBy,
+		Holdings:  f.Holdings(),
+		Positions: f.Positions(),
+	}
+}
+
+// funds are stored in portfolios.json alongside personal portfolios and are
+// protected by portMutex.
+var funds = make(map[string]*Fund)
+
+func (f *Fund) IsMember(userID string) bool {
+	if f.CreatedBy == userID {
+		return true
+	}
+	for _, member := range f.Members {
+		if member == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin reports whether the user can manage the fund's members and trades.
+func (f *Fund) IsAdmin(userID string) bool {
+	if f.CreatedBy == userID {
+		return true
+	}
+	for _, admin := range f.Admins {
+		if admin == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Contributions returns how much each member has paid into the fund's buys,
+// per coin, and the total paid per coin.
+func (f *Fund) Contributions() (map[string]map[string]float64, map[string]float64) {
+	contributions := make(map[string]map[string]float64)
+	totals := make(map[string]float64)
+	for _, tx := range f.Transactions {
+		if tx.Side != SideBuy {
+			continue
+		}
+		for userID, amount := range tx.Contributions {
+			if contributions[tx.Symbol] == nil {
+				contributions[tx.Symbol] = make(map[string]float64)
+			}
+			contributions[tx.Symbol][userID] += amount
+			totals[tx.Symbol] += amount
+		}
+	}
+	return contributions, totals
+}
+
+// Positions returns every member's contribution and share of each coin,
+// largest contribution first.
+func (f *Fund) Positions() []MemberPosition {
+	contributions, totals := f.Contributions()
+
+	var positions []MemberPosition
+	for _, userID := range f.Members {
+		position := MemberPosition{UserID: userID, Shares: make(map[string]float64)}
+		for symbol, byMember := range contributions {
+			position.Contribution += byMember[userID]
+			if totals[symbol] > 0 {
+				position.Shares[symbol] = byMember[userID] / totals[symbol]
+			}
+		}
+		positions = append(positions, position)
+	}
+	sort.SliceStable(positions, func(a, b int) bool {
+		return positions[a].Contribution > positions[b].Contribution
+	})
+	return positions
+}
+
+// Share returns the fraction of the fund's holding of symbol owned by the user.
+func (f *Fund) Share(userID, symbol string) float64 {
+	contributions, totals := f.Contributions()
+	if totals[symbol] == 0 {
+		return 0
+	}
+	return contributions[symbol][userID] / totals[symbol]
+}
+
+// Contributed reports whether the user has paid towards any of the fund's buys.
+func (f *Fund) Contributed(userID string) bool {
+	contributions, _ := f.Contributions()
+	for _, byMember := range contributions {
+		if byMember[userID] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Holdings returns the fund's positions, labelled with the fund's name.
+func (f *Fund) Holdings() []*Holding {
+	holdings := holdingsList(f.Transactions, CostBasisAverage)
+	for _, h := range holdings {
+		h.Type = "collective"
+		h.FundName = f.Name
+		h.Participants = f.Members
+		h.CreatedBy = f.CreatedBy
+	}
+	return holdings
+}
+
+// splitContribution divides a purchase's cost equally between members.
+func splitContribution(cost float64, members []string) map[string]float64 {
+	contributions := make(map[string]float64, len(members))
+	for _, userID := range members {
+		contributions[userID] = cost / float64(len(members))
+	}
+	return contributions
+}
+
+// scaleHolding returns the part of a holding owned by someone with the given share.
+func scaleHolding(h *Holding, share float64) *Holding {
+	scaled := *h
+	scaled.Amount *= share
+	scaled.CostBasis *= share
+	scaled.RealizedPL *= share
+	scaled.Share = share
+	return &scaled
+}
+
+func sameMembers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]bool, len(a))
+	for _, id := range a {
+		set[id] = true
+	}
+	for _, id := range b {
+		if !set[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// findOrCreateFundLocked returns the guild's fund with exactly these members,
+// creating it if needed. The caller must hold portMutex.
+func findOrCreateFundLocked(guildID, creatorID string, members []string, name string) *Fund {
+	for _, f := range funds {
+		if f.GuildID == guildID && sameMembers(f.Members, members) {
+			return f
+		}
+	}
+
+	fund := &Fund{
+		ID:        newTransactionID(),
+		Name:      name,
+		GuildID:   guildID,
+		CreatedBy: creatorID,
+		CreatedAt: time.Now(),
+		Members:   members,
+	}
+	funds[fund.ID] = fund
+	return fund
+}
+
+// inGuild reports whether the fund belongs to the guild. Funds migrated from
+// before groups existed have no guild and stay hidden until an admin claims
+// one for a guild with /group claim.
+func (f *Fund) inGuild(guildID string) bool {
+	return f.GuildID != "" && f.GuildID == guildID
+}
+
+// guildFundLocked returns the guild's fund with the given name (or ID).
+// The caller must hold portMutex.
+func guildFundLocked(guildID, name string) *Fund {
+	for _, f := range funds {
+		if f.inGuild(guildID) && (f.ID == name || strings.EqualFold(f.Name, name)) {
+			return f
+		}
+	}
+	return nil
+}
+
+// visibleFundsLocked returns the guild's funds the user belongs to, sorted by name.
+// The caller must hold portMutex.
+func visibleFundsLocked(guildID, userID string) []*Fund {
+	var visible []*Fund
+	for _, f := range funds {
+		if f.inGuild(guildID) && f.IsMember(userID) {
+			visible = append(visible, f)
+		}
+	}
+	sort.Slice(visible, func(a, b int) bool {
+		return visible[a].Name < visible[b].Name
+	})
+	return visible
+}
+
+// unclaimedFundsLocked returns the funds without a guild that the user
+// administers, sorted by name. The caller must hold portMutex.
+func unclaimedFundsLocked(userID string) []*Fund {
+	var unclaimed []*Fund
+	for _, f := range funds {
+		if f.GuildID == "" && f.IsAdmin(userID) {
+			unclaimed = append(unclaimed, f)
+		}
+	}
+	sort.Slice(unclaimed, func(a, b int) bool {
+		return unclaimed[a].Name < unclaimed[b].Name
+	})
+	return unclaimed
+}
+
+// migrateCollectiveToFunds moves collective transactions that were copied into
+// every participant's ledger into one fund per group of participants.
+// The caller must hold portMutex.
+func migrateCollectiveToFunds() {
+	seen := make(map[string]bool)
+	migrated := 0
+
+	for _, portfolio := range portfolios {
+		var personal []*Transaction
+		for _, tx := range portfolio.Transactions {
+			if tx.Type != "collective" {
+				personal = append(personal, tx)
+				continue
+			}
+			if seen[tx.ID] {
+				continue
+			}
+			seen[tx.ID] = true
+
+			members := mergeParticipants([]string{tx.CreatedBy}, tx.Participants)
+			fund := findOrCreateFundLocked("", tx.CreatedBy, members, fmt.Sprintf("Fund %d", len(funds)+1))
+
+			tx.Participants = nil
+			tx.Contributions = splitContribution(tx.Amount*tx.Price+tx.Fee, members)
+			fund.Transactions = append(fund.Transactions, tx)
+			sortTransactions(fund.Transactions)
+			migrated++
+		}
+		portfolio.Transactions = personal
+	}
+
+	if migrated > 0 {
+		log.Printf("Migrated %d collective transactions into %d funds", migrated, len(funds))
+	}
+}
+
+// createFundEmbeds shows a fund's holdings, holdingsPerPage per embed, each
+// with the members and the summary.
+func createFundEmbeds(s *discordgo.Session, fund fundSnapshot) []*discordgo.MessageEmbed {
+	var fields, summary []*discordgo.MessageEmbedField
+	var totalValue, totalCost, totalRealized float64
+	prices := make(map[string]float64)
+
+	for _, h := range fund.Holdings {
+		totalRealized += h.RealizedPL
+
+		price, err := getCryptoPrice(h.Symbol)
+		if err != nil {
+			log.Printf("Error getting price for %s: %v", h.Symbol, err)
+			continue
+		}
+
+		prices[h.Symbol] = price.Price
+		currentValue := price.Price * h.Amount
+		profitLoss := currentValue - h.CostBasis
+		totalValue += currentValue
+		totalCost += h.CostBasis
+
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name: strings.ToUpper(h.Symbol),
+			Value: fmt.Sprintf(
+				"Amount: %.4f\n"+
+					"Avg Cost: $%.2f\n"+
+					"Current Price: $%.2f\n"+
+					"Value: $%.2f\n"+
+					"P/L: $%.2f",
+				h.Amount,
+				h.AvgCost(),
+				price.Price,
+				currentValue,
+				profitLoss,
+			),
+			Inline: true,
+		})
+	}
+
+	totalPL := totalValue - totalCost + totalRealized
+
+	// Each member's pro-rata slice of every holding
+	var memberLines []string
+	var mentions []string
+	for _, p := range fund.Positions {
+		var value, pl float64
+		for _, h := range fund.Holdings {
+			share := p.Shares[h.Symbol]
+			pl += h.RealizedPL * share
+			if price, ok := prices[h.Symbol]; ok {
+				value += price * h.Amount * share
+				pl += (price*h.Amount - h.CostBasis) * share
+			}
+		}
+		mentions = append(mentions, fmt.Sprintf("<@%s>", p.UserID))
+		memberLines = append(memberLines, fmt.Sprintf("<@%s> In: $%.2f • Value: $%.2f • P/L: $%.2f",
+			p.UserID, p.Contribution, value, pl))
+	}
+	if len(memberLines) > 0 {
+		summary = append(summary, &discordgo.MessageEmbedField{
+			Name:  "Members",
+			Value: truncateFieldValue(strings.Join(memberLines, "\n")),
+		})
+	}
+
+	summary = append(summary, &discordgo.MessageEmbedField{
+		Name: "Fund Summary",
+		Value: fmt.Sprintf(
+			"Total Cost: $%.2f\n"+
+				"Total Value: $%.2f\n"+
+				"Unrealized P/L: $%.2f\n"+
+				"Realized P/L: $%.2f",
+			totalCost,
+			totalValue,
+			totalValue-totalCost,
+			totalRealized,
+		),
+	})
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Collective Fund: %s", fund.Name),
+		Description: fmt.Sprintf("Hey %s! Check out our collective investments!", strings.Join(mentions, " ")),
+		Color:       getColorForChange(totalPL),
+		Fields:      summary,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Created by %s • %s", formatParticipants(s, []string{fund.CreatedBy}), time.Now().Format("2006-01-02 15:04:05 MST")),
+		},
+	}
+	return splitFields(embed, fields, holdingsPerPage)
+}
+
+func handleFundAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, input string) {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+
+	portMutex.RLock()
+	candidates := visibleFundsLocked(i.GuildID, i.Member.User.ID)
+	if options := i.ApplicationCommandData().Options; len(options) > 0 && options[0].Name == "claim" {
+		candidates = unclaimedFundsLocked(i.Member.User.ID)
+	}
+	for _, f := range candidates {
+		if len(choices) == 25 {
+			break
+		}
+		if strings.Contains(strings.ToLower(f.Name), input) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  f.Name,
+				Value: f.ID,
+			})
+		}
+	}
+	portMutex.RUnlock()
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+}
@@ -3,10 +3,9 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"strconv"
-	"strings"
+	"sync"
 	"time"
 )
 
@@ -35,7 +34,7 @@ func getPriceFromBinance(id string) (*CryptoPrice, error) {
 	// Convert CoinGecko ID to Binance symbol
 	binanceSymbol := convertToBinanceSymbol(id)
 	if binanceSymbol == "" {
-		return nil, fmt.Errorf("unsupported cryptocurrency for Binance: %s", id)
+		return nil, fmt.Errorf("%w for Binance: %s", errUnsupportedCoin, id)
 	}
 
 	url := fmt.Sprintf("https://api.binance.com/api/v3/ticker/24hr?symbol=%s", binanceSymbol)
@@ -105,7 +104,7 @@ func getPriceFromCoinGecko(id string) (*CryptoPrice, error) {
 		return &price, nil
 	}
 
-	return nil, fmt.Errorf("cryptocurrency '%s' not found", id)
+	return nil, fmt.Errorf("cryptocurrency '%s' %w", id, errCoinNotFound)
 }
 
 func getPriceFromCoinMarketCap(id string) (*CryptoPrice, error) {
@@ -146,263 +145,132 @@ func getPriceFromCoinMarketCap(id string) (*CryptoPrice, error) {
 		}, nil
 	}
 
-	return nil, fmt.Errorf("cryptocurrency '%s' not found", id)
+	return nil, fmt.Errorf("cryptocurrency '%s' %w", id, errCoinNotFound)
 }
 
+var priceProviders = []*priceProvider{
+	newPriceProvider("CoinGecko", getPriceFromCoinGecko),
+	newPriceProvider("CoinMarketCap", getPriceFromCoinMarketCap),
+	newPriceProvider("Binance", getPriceFromBinance),
+}
+
+// coinGeckoMarketsProvider tracks the batched /coins/markets calls apart from
+// the price chain, since their latency says little about single price lookups.
+var coinGeckoMarketsProvider = newPriceProvider("CoinGecko Markets", nil)
+
 func getCryptoPrice(id string) (*CryptoPrice, error) {
-	// Try CoinGecko first
-	price, err := getPriceFromCoinGecko(id)
-	if err == nil {
-		return price, nil
+	if priceConsensusMode {
+		return getConsensusPrice(id)
 	}
 
-	// If CoinGecko fails with rate limit, try CoinMarketCap
-	if strings.Contains(err.Error(), "rate limit exceeded") {
-		price, err = getPriceFromCoinMarketCap(id)
-		if err == nil {
-			return price, nil
+	var lastErr error
+
+	// Try providers in order of health, skipping those with an open breaker
+	for _, provider := range orderedProviders() {
+		if !provider.allow() {
+			continue
 		}
-	}
 
-	// If CoinMarketCap fails, try Binance
-	if strings.Contains(err.Error(), "rate limit exceeded") {
-		price, err = getPriceFromBinance(id)
+		start := time.Now()
+		price, err := provider.Fetch(id)
+		provider.record(time.Since(start), err)
 		if err == nil {
 			return price, nil
 		}
+		lastErr = err
 	}
 
-	// If all APIs fail, return the original error
-	return nil, err
-}
-func getSantimentData(symbol string) (string, error) {
-	apiURL := "https://api.santiment.net/graphql"
-
-	// GraphQL query to fetch sentiment data
-	query := fmt.Sprintf(`{
-		"query": "query { 
-			getMetric(metric: \"sentiment_positive\") { 
-				timeseriesData(
-					slug: \"%s\", 
-					from: \"%s\", 
-					to: \"%s\", 
-					interval: \"1d\"
-				) { 
-					datetime 
-					value 
-				} 
-			} 
-		}"
-	}`, symbol, time.Now().Add(-24*time.Hour).Format("2006-01-02T15:04:05Z"), time.Now().Format("2006-01-02T15:04:05Z"))
-
-	req, err := http.NewRequest("POST", apiURL, strings.NewReader(query))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Add necessary headers
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", SANTIMENT_API_KEY))
-	req.Header.Add("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("non-200 response from API: %d", resp.StatusCode)
+	if lastErr == nil {
+		return nil, fmt.Errorf("all price providers are temporarily unavailable")
 	}
+	return nil, lastErr
+}
 
-	// Read and parse the response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
+// fetchWithFallback is the fallback chain of getCryptoPrice for other kinds of
+// data: it calls the fetcher registered for each provider in order of health,
+// skipping open breakers, and returns the first success.
+func fetchWithFallback[T any](fetchers map[string]func() (T, error)) (T, error) {
+	var (
+		zero    T
+		lastErr error
+	)
+	for _, provider := range orderedProviders() {
+		fetch, ok := fetchers[provider.Name]
+		if !ok || !provider.allow() {
+			continue
+		}
 
-	// Define a structure to parse response JSON
-	type TimeseriesData struct {
-		Datetime string  `json:"datetime"`
-		Value    float64 `json:"value"`
-	}
-	type MetricData struct {
-		TimeseriesData []TimeseriesData `json:"timeseriesData"`
-	}
-	type ResponseData struct {
-		GetMetric MetricData `json:"getMetric"`
-	}
-	type APIResponse struct {
-		Data ResponseData `json:"data"`
+		start := time.Now()
+		value, err := fetch()
+		provider.record(time.Since(start), err)
+		if err == nil {
+			return value, nil
+		}
+		lastErr = err
 	}
 
-	var apiResponse APIResponse
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+	if lastErr == nil {
+		return zero, fmt.Errorf("all price providers are temporarily unavailable")
 	}
+	return zero, lastErr
+}
 
-	// Analyze sentiment data
-	if len(apiResponse.Data.GetMetric.TimeseriesData) == 0 {
-		return "No sentiment data available", nil
-	}
+// fetchTracked calls fetch through a provider outside the price fallback
+// chain, so calls with their own cost, like bulk market requests, get a
+// breaker and health statistics without skewing the chain's order.
+func fetchTracked[T any](provider *priceProvider, fetch func() (T, error)) (T, error) {
+	if !provider.allow() {
+		var zero T
+		return zero, fmt.Errorf("%s is temporarily unavailable", provider.Name)
+	}
+	start := time.Now()
+	value, err := fetch()
+	provider.record(time.Since(start), err)
+	return value, err
+}
 
-	// Aggregate sentiment values
-	var totalSentiment float64
-	for _, data := range apiResponse.Data.GetMetric.TimeseriesData {
-		totalSentiment += data.Value
-	}
-	averageSentiment := totalSentiment / float64(len(apiResponse.Data.GetMetric.TimeseriesData))
-
-	// Classify sentiment
-	switch {
-	case averageSentiment > 0.75:
-		return "Very Positive 🟢", nil
-	case averageSentiment > 0.5:
-		return "Positive ✅", nil
-	case averageSentiment > 0.25:
-		return "Neutral 🟡", nil
-	case averageSentiment > 0:
-		return "Negative 🔴", nil
-	default:
-		return "Very Negative ⚠️", nil
-	}
+type cachedResponse struct {
+	value     interface{}
+	fetchedAt time.Time
+	expires   time.Time
 }
-func getSantimentDataForGreedFear(symbol string) (string, error) {
-	apiURL := "https://api.santiment.net/graphql"
-
-	// GraphQL query for sentiment and activity data
-	query := fmt.Sprintf(`{
-		"query": "query { 
-			getMetric(metric: \"sentiment_positive\") { 
-				timeseriesData(
-					slug: \"%s\", 
-					from: \"%s\", 
-					to: \"%s\", 
-					interval: \"1d\"
-				) { 
-					datetime 
-					value 
-				} 
-			}
-			getMetric(metric: \"sentiment_negative\") { 
-				timeseriesData(
-					slug: \"%s\", 
-					from: \"%s\", 
-					to: \"%s\", 
-					interval: \"1d\"
-				) { 
-					datetime 
-					value 
-				} 
-			}
-			getMetric(metric: \"social_volume_total\") { 
-				timeseriesData(
-					slug: \"%s\", 
-					from: \"%s\", 
-					to: \"%s\", 
-					interval: \"1d\"
-				) { 
-					datetime 
-					value 
-				} 
-			}
-		}"
-	}`, symbol, time.Now().Add(-24*time.Hour).Format("2006-01-02T15:04:05Z"), time.Now().Format("2006-01-02T15:04:05Z"), symbol, time.Now().Add(-24*time.Hour).Format("2006-01-02T15:04:05Z"), time.Now().Format("2006-01-02T15:04:05Z"), symbol, time.Now().Add(-24*time.Hour).Format("2006-01-02T15:04:05Z"), time.Now().Format("2006-01-02T15:04:05Z"))
-
-	req, err := http.NewRequest("POST", apiURL, strings.NewReader(query))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
 
-	// Add necessary headers
-	req.Header.Add("Authorization", "Bearer YOUR_API_KEY")
-	req.Header.Add("Content-Type", "application/json")
+// maxCachedResponses bounds responseCache, as some keys come from user input
+const maxCachedResponses = 1000
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
+var (
+	responseCache      = make(map[string]cachedResponse)
+	responseCacheMutex sync.Mutex
+)
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("non-200 response from API: %d", resp.StatusCode)
+// withCache returns the value cached under key if it's younger than ttl,
+// otherwise it calls fetch and caches a successful result. Expired entries are
+// swept on insert, and nothing new is cached while the cache is full.
+func withCache[T any](key string, ttl time.Duration, fetch func() (T, error)) (T, error) {
+	responseCacheMutex.Lock()
+	cached, ok := responseCache[key]
+	responseCacheMutex.Unlock()
+	if ok && time.Since(cached.fetchedAt) < ttl {
+		if value, ok := cached.value.(T); ok {
+			return value, nil
+		}
 	}
 
-	// Read and parse the response
-	body, err := io.ReadAll(resp.Body)
+	value, err := fetch()
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
-
-	var apiResponse struct {
-		Data struct {
-			GetMetricPositive struct {
-				TimeseriesData []struct {
-					Datetime string  `json:"datetime"`
-					Value    float64 `json:"value"`
-				} `json:"timeseriesData"`
-			} `json:"getMetric"`
-			GetMetricNegative struct {
-				TimeseriesData []struct {
-					Datetime string  `json:"datetime"`
-					Value    float64 `json:"value"`
-				} `json:"timeseriesData"`
-			} `json:"getMetric"`
-			GetMetricVolume struct {
-				TimeseriesData []struct {
-					Datetime string  `json:"datetime"`
-					Value    float64 `json:"value"`
-				} `json:"timeseriesData"`
-			} `json:"getMetric"`
-		} `json:"data"`
+		return value, err
 	}
 
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	// Aggregating data
-	var totalPositive, totalNegative, totalVolume float64
-	var countPositive, countNegative, countVolume int
-
-	for _, data := range apiResponse.Data.GetMetricPositive.TimeseriesData {
-		totalPositive += data.Value
-		countPositive++
-	}
-	for _, data := range apiResponse.Data.GetMetricNegative.TimeseriesData {
-		totalNegative += data.Value
-		countNegative++
-	}
-	for _, data := range apiResponse.Data.GetMetricVolume.TimeseriesData {
-		totalVolume += data.Value
-		countVolume++
-	}
-
-	if countPositive == 0 || countNegative == 0 || countVolume == 0 {
-		return "Insufficient data for Greed and Fear Index", nil
+	responseCacheMutex.Lock()
+	now := time.Now()
+	for other, cached := range responseCache {
+		if now.After(cached.expires) {
+			delete(responseCache, other)
+		}
 	}
-
-	// Calculate average values
-	avgPositive := totalPositive / float64(countPositive)
-	avgNegative := totalNegative / float64(countNegative)
-	avgVolume := totalVolume / float64(countVolume)
-
-	// Compute Greed-Fear Index (simplified)
-	greedScore := avgPositive + (avgVolume / 10)
-	fearScore := avgNegative - (avgVolume / 10)
-
-	// Classify index
-	switch {
-	case greedScore > fearScore*1.5:
-		return "Market sentiment: Extreme Greed üü¢", nil
-	case greedScore > fearScore:
-		return "Market sentiment: Greed ‚úÖ", nil
-	case fearScore > greedScore*1.5:
-		return "Market sentiment: Extreme Fear üî¥", nil
-	case fearScore > greedScore:
-		return "Market sentiment: Fear ‚ö†Ô∏è", nil
-	default:
-		return "Market sentiment: Neutral üü°", nil
+	if _, exists := responseCache[key]; exists || len(responseCache) < maxCachedResponses {
+		responseCache[key] = cachedResponse{value: value, fetchedAt: now, expires: now.Add(ttl)}
 	}
+	responseCacheMutex.Unlock()
+	return value, nil
 }
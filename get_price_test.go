@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWithCacheSweepsExpiredEntries(t *testing.T) {
+	responseCache = make(map[string]cachedResponse)
+	t.Cleanup(func() { responseCache = make(map[string]cachedResponse) })
+
+	fetch := func(value int) func() (int, error) {
+		return func() (int, error) { return value, nil }
+	}
+	for n := 0; n < 10; n++ {
+		withCache(fmt.Sprintf("coin:typo%d", n), time.Nanosecond, fetch(n))
+	}
+	time.Sleep(time.Millisecond)
+
+	if got, _ := withCache("coin:bitcoin", time.Minute, fetch(1)); got != 1 {
+		t.Fatalf("withCache = %d, want 1", got)
+	}
+	if len(responseCache) != 1 {
+		t.Errorf("cache holds %d entries after the sweep, want 1", len(responseCache))
+	}
+	if got, _ := withCache("coin:bitcoin", time.Minute, fetch(2)); got != 1 {
+		t.Errorf("cached value = %d, want 1", got)
+	}
+
+	for n := 0; len(responseCache) < maxCachedResponses; n++ {
+		withCache(fmt.Sprintf("coin:filler%d", n), time.Minute, fetch(n))
+	}
+	withCache("coin:overflow", time.Minute, fetch(3))
+	if _, ok := responseCache["coin:overflow"]; ok {
+		t.Error("a full cache took a new entry")
+	}
+}
@@ -0,0 +1,455 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	maxGroupNameLength = 50
+	groupsPerPage      = 5 // Member lists can fill a whole field each
+)
+
+func handleGroupCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return
+	}
+
+	switch options[0].Name {
+	case "create":
+		handleGroupCreate(s, i, options[0].Options)
+	case "add":
+		handleGroupAdd(s, i, options[0].Options)
+	case "remove":
+		handleGroupRemove(s, i, options[0].Options)
+	case "list":
+		handleGroupList(s, i)
+	case "admin":
+		handleGroupAdmin(s, i, options[0].Options)
+	case "claim":
+		handleGroupClaim(s, i, options[0].Options)
+	}
+}
+
+func respondGroup(s *discordgo.Session, i *discordgo.InteractionCreate, content string, ephemeral bool) {
+	var flags discordgo.MessageFlags
+	if ephemeral {
+		flags = discordgo.MessageFlagsEphemeral
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   flags,
+		},
+	})
+}
+
+// saveGroupChange persists the fund after a membership change.
+func saveGroupChange(fundID string) {
+	if err := saveFund(fundID); err != nil {
+		log.Printf("Error saving group: %v", err)
+	}
+}
+
+func handleGroupCreate(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	var name string
+	for _, opt := range options {
+		if opt.Name == "name" {
+			name = strings.TrimSpace(opt.StringValue())
+		}
+	}
+	if name == "" || len(name) > maxGroupNameLength {
+		respondGroup(s, i, fmt.Sprintf("⚠️ Group names must be 1-%d characters", maxGroupNameLength), true)
+		return
+	}
+
+	userID := i.Member.User.ID
+
+	portMutex.Lock()
+	if guildFundLocked(i.GuildID, name) != nil {
+		portMutex.Unlock()
+		respondGroup(s, i, fmt.Sprintf("⚠️ A group named %q already exists in this server", name), true)
+		return
+	}
+	fund := &Fund{
+		ID:        newTransactionID(),
+		Name:      name,
+		GuildID:   i.GuildID,
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+		Members:   []string{userID},
+	}
+	funds[fund.ID] = fund
+	portMutex.Unlock()
+
+	saveGroupChange(fund.ID)
+	respondGroup(s, i, fmt.Sprintf("👥 Created group **%s**. Add members with `/group add`.", name), false)
+}
+
+// roleMemberIDs returns the IDs of every non-bot member holding the role.
+func roleMemberIDs(s *discordgo.Session, guildID, roleID string) ([]string, error) {
+	var ids []string
+	after := ""
+	for {
+		members, err := s.GuildMembers(guildID, after, 1000)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching members: %w", err)
+		}
+		for _, member := range members {
+			if member.User.Bot {
+				continue
+			}
+			for _, role := range member.Roles {
+				if role == roleID {
+					ids = append(ids, member.User.ID)
+					break
+				}
+			}
+		}
+		if len(members) < 1000 {
+			return ids, nil
+		}
+		after = members[len(members)-1].User.ID
+	}
+}
+
+// groupTarget returns the group option and the user or role option of a subcommand.
+func groupTarget(options []*discordgo.ApplicationCommandInteractionDataOption) (string, string) {
+	var name, target string
+	for _, opt := range options {
+		switch opt.Name {
+		case "group":
+			name = opt.StringValue()
+		case "member":
+			target = fmt.Sprint(opt.Value)
+		}
+	}
+	return name, target
+}
+
+// manageableFundLocked looks up a group the user administers, or any group in
+// the guild for managers, returning a message explaining why when they can't
+// manage it. The caller must hold portMutex.
+func manageableFundLocked(guildID, userID, name string, manager bool) (*Fund, string) {
+	fund := findFundLocked(guildID, userID, name)
+	if fund == nil && manager {
+		fund = guildFundLocked(guildID, name)
+	}
+	if fund == nil {
+		return nil, fmt.Sprintf("⚠️ You're not a member of a group named %q", name)
+	}
+	if !fund.IsAdmin(userID) && !manager {
+		return nil, "⚠️ Only group admins and managers can manage members"
+	}
+	return fund, ""
+}
+
+func handleGroupAdd(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	name, target := groupTarget(options)
+
+	// A role adds everyone who currently has it
+	newMembers := []string{target}
+	resolved := i.ApplicationCommandData().Resolved
+	if resolved != nil && resolved.Roles[target] != nil {
+		ids, err := roleMemberIDs(s, i.GuildID, target)
+		if err != nil {
+			respondGroup(s, i, fmt.Sprintf("Error: %s", err), true)
+			return
+		}
+		if len(ids) == 0 {
+			respondGroup(s, i, "⚠️ Nobody has that role", true)
+			return
+		}
+		newMembers = ids
+	} else if resolved != nil && resolved.Users[target] != nil && resolved.Users[target].Bot {
+		respondGroup(s, i, "⚠️ Bots can't join groups", true)
+		return
+	}
+
+	manager := isManager(i)
+	portMutex.Lock()
+	fund, problem := manageableFundLocked(i.GuildID, i.Member.User.ID, name, manager)
+	if fund == nil {
+		portMutex.Unlock()
+		respondGroup(s, i, problem, true)
+		return
+	}
+	before := len(fund.Members)
+	fund.Members = mergeParticipants(fund.Members, newMembers)
+	added := fund.Members[before:]
+	fundName := fund.Name
+	portMutex.Unlock()
+
+	if len(added) == 0 {
+		respondGroup(s, i, fmt.Sprintf("Everyone is already in **%s**", fundName), true)
+		return
+	}
+
+	saveGroupChange(fund.ID)
+
+	var mentions []string
+	for _, id := range added {
+		mentions = append(mentions, fmt.Sprintf("<@%s>", id))
+	}
+	respondGroup(s, i, fmt.Sprintf("👥 Added %s to **%s**. They'll share in purchases made from now on.",
+		strings.Join(mentions, " "), fundName), false)
+}
+
+func handleGroupRemove(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	name, target := groupTarget(options)
+
+	manager := isManager(i)
+	portMutex.Lock()
+	fund, problem := manageableFundLocked(i.GuildID, i.Member.User.ID, name, manager)
+	switch {
+	case fund == nil:
+	case target == fund.CreatedBy:
+		problem = "⚠️ The group's creator can't be removed"
+	case !fund.IsMember(target):
+		problem = fmt.Sprintf("⚠️ <@%s> isn't in **%s**", target, fund.Name)
+	case fund.Contributed(target):
+		// Their contributions would otherwise vanish from everyone's shares
+		problem = fmt.Sprintf("⚠️ <@%s> still owns part of **%s**", target, fund.Name)
+	}
+	if problem != "" {
+		portMutex.Unlock()
+		respondGroup(s, i, problem, true)
+		return
+	}
+
+	var members, admins []string
+	for _, id := range fund.Members {
+		if id != target {
+			members = append(members, id)
+		}
+	}
+	for _, id := range fund.Admins {
+		if id != target {
+			admins = append(admins, id)
+		}
+	}
+	fund.Members = members
+	fund.Admins = admins
+	fundName := fund.Name
+	portMutex.Unlock()
+
+	saveGroupChange(fund.ID)
+	respondGroup(s, i, fmt.Sprintf("Removed <@%s> from **%s**", target, fundName), false)
+}
+
+func handleGroupAdmin(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	name, target := groupTarget(options)
+	admin := true
+	for _, opt := range options {
+		if opt.Name == "admin" {
+			admin = opt.BoolValue()
+		}
+	}
+
+	manager := isManager(i)
+	portMutex.Lock()
+	fund, problem := manageableFundLocked(i.GuildID, i.Member.User.ID, name, manager)
+	switch {
+	case fund == nil:
+	case !fund.IsMember(target):
+		problem = fmt.Sprintf("⚠️ <@%s> isn't in **%s**", target, fund.Name)
+	case target == fund.CreatedBy:
+		problem = "⚠️ The group's creator is always an admin"
+	}
+	if problem != "" {
+		portMutex.Unlock()
+		respondGroup(s, i, problem, true)
+		return
+	}
+
+	var admins []string
+	for _, id := range fund.Admins {
+		if id != target {
+			admins = append(admins, id)
+		}
+	}
+	if admin {
+		admins = append(admins, target)
+	}
+	fund.Admins = admins
+	fundName := fund.Name
+	portMutex.Unlock()
+
+	saveGroupChange(fund.ID)
+
+	content := fmt.Sprintf("<@%s> is now an admin of **%s**", target, fundName)
+	if !admin {
+		content = fmt.Sprintf("<@%s> is no longer an admin of **%s**", target, fundName)
+	}
+	respondGroup(s, i, content, false)
+}
+
+// handleGroupClaim moves a fund migrated from before groups existed, which
+// has no guild, into the current guild.
+func handleGroupClaim(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	name, _ := groupTarget(options)
+	userID := i.Member.User.ID
+
+	portMutex.Lock()
+	var fund *Fund
+	for _, f := range unclaimedFundsLocked(userID) {
+		if f.ID == name || strings.EqualFold(f.Name, name) {
+			fund = f
+			break
+		}
+	}
+	if fund == nil {
+		portMutex.Unlock()
+		respondGroup(s, i, fmt.Sprintf("⚠️ You don't administer an unclaimed group named %q", name), true)
+		return
+	}
+	if guildFundLocked(i.GuildID, fund.Name) != nil {
+		portMutex.Unlock()
+		respondGroup(s, i, fmt.Sprintf("⚠️ A group named %q already exists in this server", fund.Name), true)
+		return
+	}
+	fund.GuildID = i.GuildID
+	fundName := fund.Name
+	portMutex.Unlock()
+
+	saveGroupChange(fund.ID)
+	respondGroup(s, i, fmt.Sprintf("👥 **%s** now belongs to this server", fundName), false)
+}
+
+func handleGroupList(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := i.Member.User.ID
+
+	var fields []*discordgo.MessageEmbedField
+	portMutex.RLock()
+	for _, f := range visibleFundsLocked(i.GuildID, userID) {
+		var members []string
+		for _, p := range f.Positions() {
+			line := fmt.Sprintf("<@%s> $%.2f in", p.UserID, p.Contribution)
+			if f.IsAdmin(p.UserID) {
+				line += " ⭐"
+			}
+			members = append(members, line)
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name: f.Name,
+			Value: truncateFieldValue(fmt.Sprintf("%d members • %d transactions\n%s",
+				len(f.Members), len(f.Transactions), strings.Join(members, "\n"))),
+		})
+	}
+	unclaimed := len(unclaimedFundsLocked(userID))
+	portMutex.RUnlock()
+
+	if len(fields) == 0 {
+		content := "You're not in any groups. Create one with `/group create`."
+		if unclaimed > 0 {
+			content += fmt.Sprintf("\nYou administer %d group(s) from before servers were tracked, move them here with `/group claim`.", unclaimed)
+		}
+		respondGroup(s, i, content, true)
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "👥 Your Groups",
+		Color: 0x00ff00,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "⭐ admin • each coin is shared by what members paid towards it",
+		},
+	}
+	if unclaimed > 0 {
+		embed.Description = fmt.Sprintf("You administer %d group(s) from before servers were tracked, move them here with `/group claim`.", unclaimed)
+	}
+	pages := embedPages(splitFields(embed, fields, groupsPerPage), 1)
+	respondPaginated(s, i, pages, discordgo.MessageFlagsEphemeral)
+}
+
+func groupCommandOptions() []*discordgo.ApplicationCommandOption {
+	groupOption := &discordgo.ApplicationCommandOption{
+		Type:         discordgo.ApplicationCommandOptionString,
+		Name:         "group",
+		Description:  "Group name",
+		Required:     true,
+		Autocomplete: true,
+	}
+
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "create",
+			Description: "Create a named investment group",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "name",
+					Description: "Name of the group",
+					Required:    true,
+					MaxLength:   maxGroupNameLength,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "add",
+			Description: "Add a member, or everyone with a role, to a group",
+			Options: []*discordgo.ApplicationCommandOption{
+				groupOption,
+				{
+					Type:        discordgo.ApplicationCommandOptionMentionable,
+					Name:        "member",
+					Description: "Member or role to add",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "remove",
+			Description: "Remove a member from a group",
+			Options: []*discordgo.ApplicationCommandOption{
+				groupOption,
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "member",
+					Description: "Member to remove",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "List the groups you belong to",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "admin",
+			Description: "Grant or revoke a member's admin rights",
+			Options: []*discordgo.ApplicationCommandOption{
+				groupOption,
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "member",
+					Description: "Member to update",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "admin",
+					Description: "Set to false to revoke admin rights",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "claim",
+			Description: "Move a group from before servers were tracked into this server",
+			Options:     []*discordgo.ApplicationCommandOption{groupOption},
+		},
+	}
+}
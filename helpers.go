@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"strings"
+	"unicode/utf8"
 )
 
 func convertToCMCID(geckoID string) string {
@@ -19,6 +21,56 @@ func convertToBinanceSymbol(geckoID string) string {
 	return ""
 }
 
+func convertToSantimentSlug(geckoID string) string {
+	if slug, ok := convertToSantimentSlugMap[geckoID]; ok {
+		return slug
+	}
+	return geckoID
+}
+
+// resolveCryptoInfo looks up a coin in commonCryptos, falling back to using the
+// input as the CoinGecko ID.
+func resolveCryptoInfo(name string) CryptoInfo {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if cryptoInfo, exists := commonCryptos[name]; exists {
+		return cryptoInfo
+	}
+	return CryptoInfo{
+		Symbol:  strings.ToUpper(name),
+		GeckoID: name,
+	}
+}
+
+// formatLargeUSD formats big dollar amounts with a K/M/B/T suffix.
+func formatLargeUSD(value float64) string {
+	return "$" + formatLargeNumber(value)
+}
+
+// formatLargeNumber formats big amounts, like coin supplies, with a K/M/B/T suffix.
+func formatLargeNumber(value float64) string {
+	switch {
+	case abs(value) >= 1e12:
+		return fmt.Sprintf("%.2fT", value/1e12)
+	case abs(value) >= 1e9:
+		return fmt.Sprintf("%.2fB", value/1e9)
+	case abs(value) >= 1e6:
+		return fmt.Sprintf("%.2fM", value/1e6)
+	case abs(value) >= 1e3:
+		return fmt.Sprintf("%.2fK", value/1e3)
+	default:
+		return fmt.Sprintf("%.2f", value)
+	}
+}
+
+// formatUSD formats a coin price, keeping significant digits for small values.
+func formatUSD(price float64) string {
+	if price != 0 && abs(price) < 1 {
+		decimals := 3 - int(math.Floor(math.Log10(abs(price))))
+		return fmt.Sprintf("$%.*f", decimals, price)
+	}
+	return fmt.Sprintf("$%.2f", price)
+}
+
 func formatNickname(symbol string, price float64) string {
 	cryptoInfo, exists := commonCryptos[symbol]
 	if !exists {
@@ -49,9 +101,71 @@ func getColorForChange(change float64) int {
 	return 0xff0000 // Red
 }
 
+// sparkline renders values as a row of block characters scaled between the
+// minimum and maximum value.
+func sparkline(values []float64) string {
+	blocks := []rune("▁▂▃▄▅▆▇█")
+	if len(values) == 0 {
+		return ""
+	}
+
+	minValue, maxValue := values[0], values[0]
+	for _, v := range values {
+		if v < minValue {
+			minValue = v
+		}
+		if v > maxValue {
+			maxValue = v
+		}
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		idx := len(blocks) / 2
+		if maxValue > minValue {
+			idx = int((v - minValue) / (maxValue - minValue) * float64(len(blocks)-1))
+		}
+		sb.WriteRune(blocks[idx])
+	}
+	return sb.String()
+}
+
+// truncateFieldValue keeps embed field values within Discord's 1024 character
+// limit, cutting at a line break when there is one. Lengths are counted in
+// bytes, which is never less than the characters Discord counts.
+func truncateFieldValue(value string) string {
+	const (
+		maxFieldLength = 1024
+		suffix         = "\n…"
+	)
+	if len(value) <= maxFieldLength {
+		return value
+	}
+	limit := maxFieldLength - len(suffix)
+	cut := strings.LastIndex(value[:limit], "\n")
+	if cut <= 0 {
+		// Don't split a multi-byte character
+		cut = limit
+		for cut > 0 && !utf8.RuneStart(value[cut]) {
+			cut--
+		}
+	}
+	return value[:cut] + suffix
+}
+
 func abs(x float64) float64 {
 	if x < 0 {
 		return -x
 	}
 	return x
 }
+
+// geckoIDForSymbol maps a ticker such as "BTC" to its CoinGecko ID.
+func geckoIDForSymbol(symbol string) (string, bool) {
+	for _, info := range commonCryptos {
+		if strings.EqualFold(info.Symbol, symbol) {
+			return info.GeckoID, true
+		}
+	}
+	return "", false
+}
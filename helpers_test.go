@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateFieldValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"short", "bitcoin, ethereum"},
+		{"lines", strings.Repeat("0x1234567890abcdef\n", 100)},
+		{"comma joined", strings.Repeat("Layer 1, ", 200)},
+		{"multi-byte", strings.Repeat("€", 500)},
+		{"multi-byte at the cut", "x" + strings.Repeat("🚀", 400)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateFieldValue(tt.value)
+			if len(got) > 1024 {
+				t.Errorf("got %d bytes, over Discord's limit", len(got))
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("cut splits a character: %q", got[len(got)-8:])
+			}
+			if len(tt.value) <= 1024 && got != tt.value {
+				t.Errorf("a value within the limit was changed")
+			}
+			if len(tt.value) > 1024 && !strings.HasSuffix(got, "\n…") {
+				t.Errorf("truncated value is missing the ellipsis")
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -12,12 +13,18 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
+const (
+	holdingsPerPage     = 12 // Four rows of three inline fields
+	transactionsPerPage = 15
+)
+
 var (
 	portfolios = make(map[string]*Portfolio)
 	portMutex  sync.RWMutex
 	saveFile   = "portfolios.json"
 )
 
+// Investment is the legacy single-entry format, kept to migrate old portfolios.json files
 type Investment struct {
 	Amount       float64   `json:"amount"`
 	Symbol       string    `json:"symbol"`
@@ -29,8 +36,12 @@ type Investment struct {
 }
 
 type Portfolio struct {
-	UserID      string                 `json:"user_id"`
-	Investments map[string]*Investment `json:"investments"`
+	UserID          string         `json:"user_id"`
+	Transactions    []*Transaction `json:"transactions"`
+	CostBasisMethod string         `json:"cost_basis_method,omitempty"` // "fifo", "lifo" or "average"
+
+	// Target share of the portfolio per coin, in percent, used by /rebalance
+	TargetAllocations map[string]float64 `json:"target_allocations,omitempty"`
 }
 
 func formatParticipants(s *discordgo.Session, participants []string) string {
@@ -48,217 +59,527 @@ func formatParticipants(s *discordgo.Session, participants []string) string {
 	return strings.Join(names, ", ")
 }
 
-// Load portfolios from file
-func loadPortfolios() error {
+// Layout of the legacy portfolios.json file, imported into the store on first start
+//
+//	0: unversioned map of single-entry investments
+//	1: per-user transaction ledgers
+//	2: collective transactions moved into group-owned funds
+type portfolioFile struct {
+	Version    int                   `json:"version"`
+	Portfolios map[string]*Portfolio `json:"portfolios"`
+	Funds      map[string]*Fund      `json:"funds,omitempty"`
+}
+
+// decodeLegacyPortfolios parses any version of portfolios.json into portfolios
+// and funds. The caller must hold portMutex.
+func decodeLegacyPortfolios(data []byte) error {
+	var file portfolioFile
+	if err := json.Unmarshal(data, &file); err == nil && file.Version > 0 {
+		if file.Portfolios != nil {
+			portfolios = file.Portfolios
+		}
+		if file.Funds != nil {
+			funds = file.Funds
+		}
+		if file.Version < 2 {
+			migrateCollectiveToFunds()
+		}
+		return nil
+	}
+
+	// Files without a version are the old map of single-entry investments
+	var legacy map[string]*struct {
+		UserID      string                 `json:"user_id"`
+		Investments map[string]*Investment `json:"investments"`
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+
+	for userID, old := range legacy {
+		portfolio := &Portfolio{UserID: userID}
+		for _, inv := range old.Investments {
+			portfolio.Transactions = append(portfolio.Transactions, migrateInvestment(inv))
+		}
+		sortTransactions(portfolio.Transactions)
+		portfolios[userID] = portfolio
+	}
+	log.Printf("Migrated %d legacy portfolios to the transaction ledger", len(portfolios))
+	migrateCollectiveToFunds()
+	return nil
+}
+
+// migrateInvestment converts a legacy investment into a buy transaction. The ID
+// is derived from the investment so copies of a collective investment stored in
+// several portfolios map to the same transaction.
+func migrateInvestment(inv *Investment) *Transaction {
+	return &Transaction{
+		ID:           fmt.Sprintf("legacy-%s-%s-%d", inv.CreatedBy, inv.Symbol, inv.CreatedAt.UnixNano()),
+		Side:         SideBuy,
+		Symbol:       inv.Symbol,
+		Amount:       inv.Amount,
+		Price:        inv.BuyPrice,
+		Type:         inv.Type,
+		Participants: inv.Participants,
+		CreatedBy:    inv.CreatedBy,
+		Timestamp:    inv.CreatedAt,
+	}
+}
+
+// importLegacyPortfolios copies portfolios.json into the store. The file is
+// left in place but no longer read.
+func importLegacyPortfolios(tx StoreTx) error {
+	data, err := os.ReadFile(saveFile)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
 	portMutex.Lock()
 	defer portMutex.Unlock()
 
-	data, err := os.ReadFile(saveFile)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
+	if err := decodeLegacyPortfolios(data); err != nil {
+		return fmt.Errorf("error reading %s: %w", saveFile, err)
+	}
+	for userID, portfolio := range portfolios {
+		if err := putJSON(tx, bucketPortfolios, userID, portfolio); err != nil {
+			return err
+		}
+	}
+	for fundID, fund := range funds {
+		if err := putJSON(tx, bucketFunds, fundID, fund); err != nil {
+			return err
 		}
-		return err
 	}
 
-	return json.Unmarshal(data, &portfolios)
+	log.Printf("Imported %d portfolios and %d funds from %s", len(portfolios), len(funds), saveFile)
+	return nil
 }
 
-// Save portfolios to file
-func savePortfolios() error {
+// savePortfolio writes the user's portfolio to the store. Call it after
+// releasing portMutex; the read lock is held until the write commits so
+// concurrent saves are written in order.
+func savePortfolio(userID string) error {
 	portMutex.RLock()
 	defer portMutex.RUnlock()
 
-	data, err := json.MarshalIndent(portfolios, "", "  ")
+	return store.Update(func(tx StoreTx) error {
+		if portfolio, exists := portfolios[userID]; exists {
+			return putJSON(tx, bucketPortfolios, userID, portfolio)
+		}
+		return tx.Delete(bucketPortfolios, userID)
+	})
+}
+
+// saveLedger saves the fund when fundID is set, otherwise the user's portfolio.
+func saveLedger(userID, fundID string) {
+	var err error
+	if fundID != "" {
+		err = saveFund(fundID)
+	} else {
+		err = savePortfolio(userID)
+	}
 	if err != nil {
-		return err
+		log.Printf("Error saving portfolio: %v", err)
 	}
-
-	return os.WriteFile(saveFile, data, 0644)
 }
 
-// Hàm helper để lấy hoặc tạo portfolio cho user
-func getOrCreatePortfolio(userID string) *Portfolio {
-	portMutex.Lock()
-	defer portMutex.Unlock()
+// saveFund writes the fund to the store, like savePortfolio.
+func saveFund(fundID string) error {
+	portMutex.RLock()
+	defer portMutex.RUnlock()
+
+	return store.Update(func(tx StoreTx) error {
+		if fund, exists := funds[fundID]; exists {
+			return putJSON(tx, bucketFunds, fundID, fund)
+		}
+		return tx.Delete(bucketFunds, fundID)
+	})
+}
 
+// getOrCreatePortfolioLocked returns the user's portfolio, creating it if needed.
+// The caller must hold portMutex.
+func getOrCreatePortfolioLocked(userID string) *Portfolio {
 	if portfolio, exists := portfolios[userID]; exists {
 		return portfolio
 	}
 
 	// Tạo portfolio mới nếu chưa tồn tại
 	portfolios[userID] = &Portfolio{
-		UserID:      userID,
-		Investments: make(map[string]*Investment),
+		UserID: userID,
 	}
 	return portfolios[userID]
 }
-func handleSetInvestCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	options := i.ApplicationCommandData().Options
-	symbol := strings.ToLower(options[0].StringValue())
-	amount := options[1].FloatValue()
-	buyPrice := options[2].FloatValue()
 
-	// Default values
-	investType := "personal"
-	var participants []string
+// costBasisMethodLocked returns the user's preferred cost basis method.
+// The caller must hold portMutex.
+func costBasisMethodLocked(userID string) string {
+	if portfolio, exists := portfolios[userID]; exists {
+		return normalizeCostBasisMethod(portfolio.CostBasisMethod)
+	}
+	return CostBasisAverage
+}
 
-	// Process options
-	for _, opt := range options[3:] {
-		switch opt.Name {
-		case "type":
-			investType = strings.ToLower(opt.StringValue())
-		case "participants":
-			// Split the mentions string by spaces
-			mentionsStr := opt.StringValue()
-			mentions := strings.Fields(mentionsStr)
-
-			// Process each mention
-			for _, mention := range mentions {
-				userID := strings.Trim(mention, "<@!> ")
-				if userID != "" {
-					_, err := s.User(userID)
-					if err == nil {
-						participants = append(participants, userID)
-					}
-				}
-			}
-		}
+// parseTransactionDate parses an optional YYYY-MM-DD date, defaulting to now.
+func parseTransactionDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now(), nil
 	}
-	var participantNames []string
-	for _, userID := range participants {
-		if user, err := s.User(userID); err == nil {
-			participantNames = append(participantNames, user.Username)
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+	}
+	now := time.Now()
+	if date.After(now) {
+		return time.Time{}, fmt.Errorf("date %s is in the future", value)
+	}
+	// Today means now, so a trade dated today sorts after today's earlier ones
+	if date.Format("2006-01-02") == now.UTC().Format("2006-01-02") {
+		return now, nil
+	}
+	return date, nil
+}
+
+func parseGeckoID(symbol string) string {
+	geckoID := strings.ToLower(symbol)
+	if strings.Contains(geckoID, "(") {
+		parts := strings.Split(geckoID, "(")
+		geckoID = strings.TrimSpace(strings.TrimRight(parts[1], ")"))
+	}
+	return geckoID
+}
+
+// investOrder is one buy, from the /setinvest options or the guided form.
+type investOrder struct {
+	Symbol       string
+	Amount       float64
+	Price        float64
+	Fee          float64
+	Note         string
+	Date         string
+	Group        string   // Group name or ID, empty for a personal buy
+	Participants []string // Members splitting a group buy, empty for all of them
+}
+
+func handleSetInvestCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var order investOrder
+	var hasAmount, hasPrice bool
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "symbol":
+			order.Symbol = strings.ToLower(opt.StringValue())
+		case "amount":
+			order.Amount, hasAmount = opt.FloatValue(), true
+		case "price":
+			order.Price, hasPrice = opt.FloatValue(), true
+		case "group":
+			order.Group = opt.StringValue()
+		case "fee":
+			order.Fee = opt.FloatValue()
+		case "note":
+			order.Note = opt.StringValue()
+		case "date":
+			order.Date = opt.StringValue()
 		}
 	}
 
-	// For collective investments, require participants
-	if investType == "collective" && len(participants) == 0 {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "Collective investments require at least one participant. Please mention the participants.",
-			},
-		})
+	// Without the trade itself, walk the user through the guided form
+	if order.Symbol == "" || !hasAmount || !hasPrice {
+		openInvestForm(s, i, order)
 		return
 	}
 
-	userID := i.Member.User.ID
-	geckoID := symbol
-	if strings.Contains(symbol, "(") {
-		parts := strings.Split(symbol, "(")
-		geckoID = strings.TrimSpace(strings.TrimRight(parts[1], ")"))
+	response, public, err := recordInvestment(s, i.GuildID, i.Member.User.ID, order)
+	flags := discordgo.MessageFlagsEphemeral
+	if err != nil {
+		response = fmt.Sprintf("⚠️ %v", err)
+	} else if public {
+		flags = 0
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: response,
+			Flags:   flags,
+		},
+	})
+}
+
+// recordInvestment validates and records a buy. It returns the confirmation
+// and whether it should be posted publicly, which group buys are.
+func recordInvestment(s *discordgo.Session, guildID, userID string, order investOrder) (string, bool, error) {
+	timestamp, err := parseTransactionDate(order.Date)
+	if err != nil {
+		return "", false, err
+	}
+	if order.Amount <= 0 || order.Price < 0 || order.Fee < 0 {
+		return "", false, fmt.Errorf("amount must be positive and price and fee can't be negative")
 	}
 
-	// Create investment entry
-	investment := &Investment{
-		Amount:    amount,
+	investType := "personal"
+	if order.Group != "" {
+		investType = "collective"
+	}
+	geckoID := parseGeckoID(order.Symbol)
+
+	// Create buy transaction
+	tx := &Transaction{
+		ID:        newTransactionID(),
+		Side:      SideBuy,
 		Symbol:    geckoID,
-		BuyPrice:  buyPrice,
+		Amount:    order.Amount,
+		Price:     order.Price,
+		Fee:       order.Fee,
+		Note:      order.Note,
 		Type:      investType,
 		CreatedBy: userID,
-		CreatedAt: time.Now(),
+		Timestamp: timestamp,
 	}
 
+	portMutex.Lock()
+	var members []string
+	var fundID, groupName string
 	if investType == "collective" {
-		investment.Participants = participants
-	}
-
-	// Save to creator's portfolio
-	portfolio := getOrCreatePortfolio(userID)
-	portfolio.Investments[geckoID] = investment
-
-	// For collective investments, create reference in participants' portfolios
-	if investType == "collective" {
-		for _, participantID := range participants {
-			if participantID != userID { // Skip creator as they already have it
-				partPortfolio := getOrCreatePortfolio(participantID)
-				partPortfolio.Investments[geckoID] = investment
+		// Collective buys belong to the group's fund, with the cost split
+		// equally between its current members or the chosen participants
+		fund := findFundLocked(guildID, userID, order.Group)
+		if fund == nil {
+			portMutex.Unlock()
+			return "", false, fmt.Errorf("you're not a member of a group named %q", order.Group)
+		}
+		if !fund.IsAdmin(userID) {
+			portMutex.Unlock()
+			return "", false, fmt.Errorf("only group admins can record trades")
+		}
+		for _, participant := range order.Participants {
+			if !fund.IsMember(participant) {
+				portMutex.Unlock()
+				return "", false, fmt.Errorf("<@%s> isn't a member of %s", participant, fund.Name)
 			}
 		}
-	}
 
-	if err := savePortfolios(); err != nil {
-		log.Printf("Error saving portfolios: %v", err)
+		groupName, fundID = fund.Name, fund.ID
+		members = append(members, fund.Members...)
+		if len(order.Participants) > 0 {
+			members = append([]string{}, order.Participants...)
+		}
+		tx.Contributions = splitContribution(order.Amount*order.Price+order.Fee, members)
+		fund.Transactions = append(fund.Transactions, tx)
+		sortTransactions(fund.Transactions)
+	} else {
+		// Save to creator's portfolio
+		portfolio := getOrCreatePortfolioLocked(userID)
+		portfolio.Transactions = append(portfolio.Transactions, tx)
+		sortTransactions(portfolio.Transactions)
 	}
+	portMutex.Unlock()
+
+	saveLedger(userID, fundID)
 
 	// Create response message
 	var response string
 	if investType == "collective" {
-		participantsStr := "No participants"
-		if len(participantNames) > 0 {
-			participantsStr = strings.Join(participantNames, ", ")
-		}
-		response = fmt.Sprintf("Collective investment set: %.4f %s at $%.2f per coin\nParticipants: %s",
-			amount, strings.ToUpper(geckoID), buyPrice, participantsStr)
+		response = fmt.Sprintf("Collective investment added to %s: %.4f %s at $%.2f per coin\nParticipants: %s",
+			groupName, order.Amount, strings.ToUpper(geckoID), order.Price, formatParticipants(s, members))
 	} else {
-		response = fmt.Sprintf("Personal investment set: %.4f %s at $%.2f per coin",
-			amount, strings.ToUpper(geckoID), buyPrice)
+		response = fmt.Sprintf("Personal investment added: %.4f %s at $%.2f per coin",
+			order.Amount, strings.ToUpper(geckoID), order.Price)
+	}
+	if order.Fee > 0 {
+		response += fmt.Sprintf(" (fee $%.2f)", order.Fee)
 	}
+	return response, investType == "collective", nil
+}
 
-	interactResp := &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: response,
-			Flags: func() discordgo.MessageFlags {
-				if investType == "personal" {
-					return discordgo.MessageFlagsEphemeral
-				}
-				return 0
-			}(),
-		},
+// findFundLocked returns the guild's fund with the given name (or ID) if the
+// user belongs to it. The caller must hold portMutex.
+func findFundLocked(guildID, userID, name string) *Fund {
+	if f := guildFundLocked(guildID, name); f != nil && f.IsMember(userID) {
+		return f
 	}
-	s.InteractionRespond(i.Interaction, interactResp)
+	return nil
 }
-func handleAssetsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	requestingUserID := i.Member.User.ID
 
-	var filterType string
-	if len(i.ApplicationCommandData().Options) > 0 {
-		filterType = strings.ToLower(i.ApplicationCommandData().Options[0].StringValue())
+func handleSellInvestCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var symbol, note, date, fundName string
+	var amount, sellPrice, fee float64
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "symbol":
+			symbol = opt.StringValue()
+		case "amount":
+			amount = opt.FloatValue()
+		case "price":
+			sellPrice = opt.FloatValue()
+		case "fee":
+			fee = opt.FloatValue()
+		case "note":
+			note = opt.StringValue()
+		case "date":
+			date = opt.StringValue()
+		case "group":
+			fundName = opt.StringValue()
+		}
 	}
 
-	portMutex.RLock()
-	defer portMutex.RUnlock()
+	respond := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
 
-	// Get all visible collective investments
-	visibleCollectiveInvestments := make(map[string]*Investment)
-	for _, p := range portfolios {
-		for symbol, inv := range p.Investments {
-			if inv.Type == "collective" {
-				isParticipant := false
-				for _, participantID := range inv.Participants {
-					if participantID == requestingUserID {
-						isParticipant = true
-						break
-					}
-				}
-				if isParticipant || inv.CreatedBy == requestingUserID {
-					visibleCollectiveInvestments[symbol] = inv
-				}
+	timestamp, err := parseTransactionDate(date)
+	if err != nil {
+		respond(fmt.Sprintf("⚠️ %v", err))
+		return
+	}
+	if amount <= 0 || sellPrice < 0 || fee < 0 {
+		respond("⚠️ Amount must be positive and price and fee can't be negative")
+		return
+	}
+
+	userID := i.Member.User.ID
+	geckoID := parseGeckoID(symbol)
+
+	tx := &Transaction{
+		ID:        newTransactionID(),
+		Side:      SideSell,
+		Symbol:    geckoID,
+		Amount:    amount,
+		Price:     sellPrice,
+		Fee:       fee,
+		Note:      note,
+		Type:      "personal",
+		CreatedBy: userID,
+		Timestamp: timestamp,
+	}
+
+	portMutex.Lock()
+	// Sells land in the fund's ledger or the user's own one
+	var ledger *[]*Transaction
+	var fundID string
+	method := costBasisMethodLocked(userID)
+	if fundName != "" {
+		fund := findFundLocked(i.GuildID, userID, fundName)
+		if fund == nil {
+			portMutex.Unlock()
+			respond(fmt.Sprintf("⚠️ You're not a member of a group named %q", fundName))
+			return
+		}
+		if !fund.IsAdmin(userID) {
+			portMutex.Unlock()
+			respond("⚠️ Only group admins can record trades")
+			return
+		}
+		ledger, fundID = &fund.Transactions, fund.ID
+		method = CostBasisAverage
+		tx.Type = "collective"
+	} else {
+		ledger = &getOrCreatePortfolioLocked(userID).Transactions
+	}
+
+	// The sell has to be covered by the holdings on its date, and mustn't
+	// leave a later sell uncovered. Sells that were already uncovered before
+	// this one don't block it.
+	previous, _ := oversoldSell(*ledger, geckoID)
+	*ledger = append(*ledger, tx)
+	sortTransactions(*ledger)
+	if oversold, held := oversoldSell(*ledger, geckoID); oversold != nil && (oversold == tx || previous == nil) {
+		for idx, other := range *ledger {
+			if other == tx {
+				*ledger = append((*ledger)[:idx], (*ledger)[idx+1:]...)
+				break
 			}
 		}
+		portMutex.Unlock()
+		if oversold == tx {
+			respond(fmt.Sprintf("⚠️ Only %.4f %s was held on %s", held, strings.ToUpper(geckoID), tx.Timestamp.Format("2006-01-02")))
+		} else {
+			respond(fmt.Sprintf("⚠️ That would leave the sell of %.4f %s on %s uncovered",
+				oversold.Amount, strings.ToUpper(geckoID), oversold.Timestamp.Format("2006-01-02")))
+		}
+		return
 	}
+	holding := replayLedger(*ledger, method).Holdings[geckoID]
+	portMutex.Unlock()
 
-	// Get personal investments of the requesting user
-	personalInvestments := make(map[string]*Investment)
-	if portfolio, exists := portfolios[requestingUserID]; exists {
-		for symbol, inv := range portfolio.Investments {
-			if inv.Type == "personal" && inv.CreatedBy == requestingUserID {
-				personalInvestments[symbol] = inv
+	saveLedger(userID, fundID)
+
+	respond(fmt.Sprintf("Sold %.4f %s at $%.2f per coin\nRemaining: %.4f %s • Realized P/L: $%.2f",
+		amount, strings.ToUpper(geckoID), sellPrice, holding.Amount, strings.ToUpper(geckoID), holding.RealizedPL))
+}
+
+// personalTransactionsLocked returns the user's own ledger.
+// The caller must hold portMutex.
+func personalTransactionsLocked(userID string) []*Transaction {
+	var personal []*Transaction
+	if portfolio, exists := portfolios[userID]; exists {
+		for _, tx := range portfolio.Transactions {
+			if tx.Type != "collective" {
+				personal = append(personal, tx)
 			}
 		}
 	}
+	return personal
+}
+
+// holdingsList returns the holdings with open positions or realized gains, sorted by symbol.
+func holdingsList(txs []*Transaction, method string) []*Holding {
+	var list []*Holding
+	for _, h := range replayLedger(txs, method).Holdings {
+		if h.Amount > 0 || h.RealizedPL != 0 {
+			list = append(list, h)
+		}
+	}
+	sort.Slice(list, func(a, b int) bool {
+		return list[a].Symbol < list[b].Symbol
+	})
+	return list
+}
+
+const (
+	assetsRefreshPrefix = "assets.refresh"
+	investRemovePrefix  = "invest.rm"
+)
+
+// assetsRef is the payload of the /assets quick actions.
+type assetsRef struct {
+	View  string `json:"v"`
+	Owner string `json:"o"`
+}
+
+// holdingRef is a personal holding picked in the remove menu.
+type holdingRef struct {
+	Symbol string `json:"s"`
+}
+
+func handleAssetsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var filterType string
+	if len(i.ApplicationCommandData().Options) > 0 {
+		filterType = strings.ToLower(i.ApplicationCommandData().Options[0].StringValue())
+	}
 
 	switch filterType {
+	case "allocation":
+		portMutex.RLock()
+		personalHoldings := holdingsList(personalTransactionsLocked(i.Member.User.ID), costBasisMethodLocked(i.Member.User.ID))
+		portMutex.RUnlock()
+		handleAllocationView(s, i, personalHoldings)
+
 	case "personal":
 		// Handle personal filter...
-		if len(personalInvestments) > 0 {
+		if p := assetsPaginator(s, i.GuildID, i.Member.User, "personal"); p != nil {
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
-					Flags:  discordgo.MessageFlagsEphemeral,
-					Embeds: []*discordgo.MessageEmbed{createPortfolioEmbed(s, personalInvestments, "Personal", i.Member.User.Username)},
+					Embeds:     p.embeds(),
+					Components: p.components(),
+					Flags:      discordgo.MessageFlagsEphemeral,
 				},
 			})
 		} else {
@@ -273,11 +594,12 @@ func handleAssetsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	case "collective":
 		// Handle collective filter...
-		if len(visibleCollectiveInvestments) > 0 {
+		if p := assetsPaginator(s, i.GuildID, i.Member.User, "collective"); p != nil {
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
-					Embeds: []*discordgo.MessageEmbed{createPortfolioEmbed(s, visibleCollectiveInvestments, "Collective", i.Member.User.Username)},
+					Embeds:     p.embeds(),
+					Components: p.components(),
 				},
 			})
 		} else {
@@ -290,122 +612,264 @@ func handleAssetsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		}
 
 	default:
-		// Send public collective investments message first (visible to everyone except command caller)
-		if len(visibleCollectiveInvestments) > 0 {
-			err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		// Personal holdings plus the caller's share of each fund
+		complete := assetsPaginator(s, i.GuildID, i.Member.User, "complete")
+		funds := assetsPaginator(s, i.GuildID, i.Member.User, "collective")
+
+		if funds == nil {
+			if complete == nil {
+				s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+					Type: discordgo.InteractionResponseChannelMessageWithSource,
+					Data: &discordgo.InteractionResponseData{
+						Content: "You have no investments.",
+						Flags:   discordgo.MessageFlagsEphemeral,
+					},
+				})
+				return
+			}
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
-					Embeds: []*discordgo.MessageEmbed{createPortfolioEmbed(s, visibleCollectiveInvestments, "Collective", i.Member.User.Username)},
+					Embeds:     complete.embeds(),
+					Components: complete.components(),
+					Flags:      discordgo.MessageFlagsEphemeral,
 				},
 			})
-			if err != nil {
-				log.Printf("Error sending collective investments: %v", err)
-				return
-			}
+			return
+		}
+
+		// Send public collective investments message first
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds:     funds.embeds(),
+				Components: funds.components(),
+			},
+		})
+		if err != nil {
+			log.Printf("Error sending collective investments: %v", err)
+			return
 		}
 
 		// Send private complete portfolio only to the command caller
-		// Regardless of whether they have collective investments or not
-		completePortfolio := make(map[string]*Investment)
-		// Add all personal investments
-		for k, v := range personalInvestments {
-			completePortfolio[k] = v
-		}
-		// Add all collective investments
-		for k, v := range visibleCollectiveInvestments {
-			completePortfolio[k] = v
-		}
-
-		// Only send the follow-up message if there are any investments to show
-		if len(completePortfolio) > 0 {
-			_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-				Embeds: []*discordgo.MessageEmbed{createPortfolioEmbed(s, completePortfolio, "Complete", i.Member.User.Username)},
-				Flags:  discordgo.MessageFlagsEphemeral,
-			})
-			if err != nil {
-				log.Printf("Error sending complete portfolio: %v", err)
-			}
+		if complete == nil {
+			return
+		}
+		_, err = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Embeds:     complete.embeds(),
+			Components: complete.components(),
+			Flags:      discordgo.MessageFlagsEphemeral,
+		})
+		if err != nil {
+			log.Printf("Error sending complete portfolio: %v", err)
 		}
 	}
 }
 
-func createPortfolioEmbed(s *discordgo.Session, investments map[string]*Investment, portfolioType string, username string) *discordgo.MessageEmbed {
-	var fields []*discordgo.MessageEmbedField
-	var totalValue, totalCost float64
-	// Collect all unique participants
-	uniqueParticipants := make(map[string]bool)
-	if portfolioType == "Collective" {
-		for _, inv := range investments {
-			if inv.Type == "collective" {
-				// Add creator
-				uniqueParticipants[inv.CreatedBy] = true
-				// Add participants
-				for _, participantID := range inv.Participants {
-					uniqueParticipants[participantID] = true
+// assetsPaginator builds one /assets view for the user: "personal",
+// "complete" (personal holdings plus their share of each fund) or
+// "collective" (one page per fund). It returns nil when there's nothing to
+// show.
+func assetsPaginator(s *discordgo.Session, guildID string, user *discordgo.User, view string) *paginator {
+	portMutex.RLock()
+	holdings := holdingsList(personalTransactionsLocked(user.ID), costBasisMethodLocked(user.ID))
+
+	// Each fund is shown once; the caller's own view only counts their share of it
+	var fundSnapshots []fundSnapshot
+	for _, f := range visibleFundsLocked(guildID, user.ID) {
+		fundSnapshots = append(fundSnapshots, f.snapshot())
+
+		if view != "complete" {
+			continue
+		}
+		for _, h := range f.Holdings() {
+			if share := f.Share(user.ID, h.Symbol); share > 0 {
+				holdings = append(holdings, scaleHolding(h, share))
+			}
+		}
+	}
+	portMutex.RUnlock()
+
+	refresh := discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "🔄 Refresh",
+				Style:    discordgo.SecondaryButton,
+				CustomID: componentID(assetsRefreshPrefix, quickActionTTL, assetsRef{View: view, Owner: user.ID}),
+			},
+		},
+	}
+
+	if view == "collective" {
+		// One page per fund embed, labelled with the fund's name in the jump menu
+		var pages [][]*discordgo.MessageEmbed
+		var labels []string
+		for _, snapshot := range fundSnapshots {
+			embeds := createFundEmbeds(s, snapshot)
+			for idx, embed := range embeds {
+				label := snapshot.Name
+				if len(embeds) > 1 {
+					label = fmt.Sprintf("%s (%d/%d)", snapshot.Name, idx+1, len(embeds))
 				}
+				pages = append(pages, []*discordgo.MessageEmbed{embed})
+				labels = append(labels, label)
 			}
 		}
+		if len(pages) == 0 {
+			return nil
+		}
+		p := newPaginator(user.ID, pages, labels...)
+		p.actions = func(int) []discordgo.MessageComponent {
+			return []discordgo.MessageComponent{refresh}
+		}
+		return p
 	}
-	// Create mentions string
-	var mentions []string
-	for userID := range uniqueParticipants {
-		mentions = append(mentions, fmt.Sprintf("<@%s>", userID))
+
+	if len(holdings) == 0 {
+		return nil
+	}
+	portfolioType := "Complete"
+	if view == "personal" {
+		portfolioType = "Personal"
+	}
+
+	p := newPaginator(user.ID, embedPages(createPortfolioEmbeds(s, holdings, portfolioType, user.Username), 1))
+	p.actions = func(page int) []discordgo.MessageComponent {
+		rows := []discordgo.MessageComponent{refresh}
+
+		// A menu to remove one of the personal holdings on this page
+		var options []discordgo.SelectMenuOption
+		for idx := page * holdingsPerPage; idx < len(holdings) && idx < (page+1)*holdingsPerPage; idx++ {
+			if h := holdings[idx]; h.FundName == "" {
+				options = append(options, discordgo.SelectMenuOption{
+					Label: strings.ToUpper(h.Symbol),
+					Value: encodePayload(holdingRef{Symbol: h.Symbol}),
+				})
+			}
+		}
+		if len(options) > 0 {
+			rows = append(rows, discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.SelectMenu{
+						MenuType:    discordgo.StringSelectMenu,
+						CustomID:    componentID(investRemovePrefix, paginationTTL, assetsRef{View: view, Owner: user.ID}),
+						Placeholder: "🗑️ Remove an investment…",
+						Options:     options,
+					},
+				},
+			})
+		}
+		return rows
+	}
+	return p
+}
+
+// updateAssetsMessage replaces an /assets message with a fresh view.
+func updateAssetsMessage(s *discordgo.Session, i *discordgo.InteractionCreate, ref assetsRef) {
+	data := &discordgo.InteractionResponseData{
+		Content:    "Nothing to show anymore.",
+		Embeds:     []*discordgo.MessageEmbed{},
+		Components: []discordgo.MessageComponent{},
+	}
+	if p := assetsPaginator(s, i.GuildID, i.Member.User, ref.View); p != nil {
+		data = &discordgo.InteractionResponseData{
+			Embeds:     p.embeds(),
+			Components: p.components(),
+		}
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: data,
+	})
+}
+
+func handleAssetsRefresh(s *discordgo.Session, i *discordgo.InteractionCreate, ref assetsRef) {
+	if i.Member.User.ID != ref.Owner {
+		respondComponentError(s, i, "Run `/assets` to see your own investments")
+		return
+	}
+	updateAssetsMessage(s, i, ref)
+}
+
+// handleRemoveInvestSelect removes the holding picked from an /assets page
+// and refreshes the message.
+func handleRemoveInvestSelect(s *discordgo.Session, i *discordgo.InteractionCreate, ref assetsRef) {
+	if i.Member.User.ID != ref.Owner {
+		respondComponentError(s, i, "Run `/assets` to see your own investments")
+		return
+	}
+	holding, err := selectedPayload[holdingRef](i)
+	if err != nil {
+		return
 	}
 
-	for _, inv := range investments {
-		price, err := getCryptoPrice(inv.Symbol)
+	content := removeInvestment(i.GuildID, i.Member.User.ID, "", holding.Symbol)
+	updateAssetsMessage(s, i, ref)
+	s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: content,
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+}
+
+// createPortfolioEmbeds shows the holdings, holdingsPerPage per embed, each
+// with the portfolio summary.
+func createPortfolioEmbeds(s *discordgo.Session, holdings []*Holding, portfolioType string, username string) []*discordgo.MessageEmbed {
+	var fields []*discordgo.MessageEmbedField
+	var totalValue, totalCost, totalRealized float64
+
+	for _, h := range holdings {
+		totalRealized += h.RealizedPL
+
+		name := fmt.Sprintf("%s (%s)", strings.ToUpper(h.Symbol), h.Type)
+		if h.FundName != "" {
+			name = fmt.Sprintf("%s (%s, %.1f%%)", strings.ToUpper(h.Symbol), h.FundName, h.Share*100)
+		}
+
+		// Still list the holding, the remove menu relies on one field per holding
+		price, err := getCryptoPrice(h.Symbol)
 		if err != nil {
-			log.Printf("Error getting price for %s: %v", inv.Symbol, err)
+			log.Printf("Error getting price for %s: %v", h.Symbol, err)
+			fields = append(fields, &discordgo.MessageEmbedField{
+				Name:   name,
+				Value:  fmt.Sprintf("Amount: %.4f\nAvg Cost: $%.2f\nCurrent Price: unavailable", h.Amount, h.AvgCost()),
+				Inline: true,
+			})
 			continue
 		}
 
-		currentValue := price.Price * inv.Amount
-		initialCost := inv.BuyPrice * inv.Amount
-		profitLoss := currentValue - initialCost
-		profitLossPercent := (profitLoss / initialCost) * 100
+		currentValue := price.Price * h.Amount
+		profitLoss := currentValue - h.CostBasis
+		profitLossPercent := 0.0
+		if h.CostBasis > 0 {
+			profitLossPercent = (profitLoss / h.CostBasis) * 100
+		}
 
 		totalValue += currentValue
-		totalCost += initialCost
-
-		var description string
-		if inv.Type == "collective" {
-			description = fmt.Sprintf(
-				"Amount: %.4f\n"+
-					"Buy Price: $%.2f\n"+
-					"Current Price: $%.2f\n"+
-					"Value: $%.2f\n"+
-					"P/L: $%.2f (%.2f%%)\n"+
-					"Participants: %s\n"+
-					"Created by: <@%s>\n"+
-					"Created at: %s",
-				inv.Amount,
-				inv.BuyPrice,
-				price.Price,
-				currentValue,
-				profitLoss,
-				profitLossPercent,
-				formatParticipants(s, inv.Participants),
-				inv.CreatedBy,
-				inv.CreatedAt.Format("2006-01-02 15:04:05"),
-			)
-		} else {
-			description = fmt.Sprintf(
-				"Amount: %.4f\n"+
-					"Buy Price: $%.2f\n"+
-					"Current Price: $%.2f\n"+
-					"Value: $%.2f\n"+
-					"P/L: $%.2f (%.2f%%)",
-				inv.Amount,
-				inv.BuyPrice,
-				price.Price,
-				currentValue,
-				profitLoss,
-				profitLossPercent,
-			)
+		totalCost += h.CostBasis
+
+		description := fmt.Sprintf(
+			"Amount: %.4f\n"+
+				"Avg Cost: $%.2f\n"+
+				"Current Price: $%.2f\n"+
+				"Value: $%.2f\n"+
+				"P/L: $%.2f (%.2f%%)",
+			h.Amount,
+			h.AvgCost(),
+			price.Price,
+			currentValue,
+			profitLoss,
+			profitLossPercent,
+		)
+		if h.RealizedPL != 0 {
+			description += fmt.Sprintf("\nRealized P/L: $%.2f", h.RealizedPL)
+		}
+
+		if h.FundName == "" {
+			description += fmt.Sprintf("\nTransactions: %d", h.TxCount)
 		}
 
 		fields = append(fields, &discordgo.MessageEmbedField{
-			Name:   fmt.Sprintf("%s (%s)", strings.ToUpper(inv.Symbol), inv.Type),
+			Name:   name,
 			Value:  description,
 			Inline: true,
 		})
@@ -418,74 +882,310 @@ func createPortfolioEmbed(s *discordgo.Session, investments map[string]*Investme
 		totalPLPercent = (totalPL / totalCost) * 100
 	}
 
-	fields = append(fields, &discordgo.MessageEmbedField{
+	summary := &discordgo.MessageEmbedField{
 		Name: fmt.Sprintf("%s Portfolio Summary", portfolioType),
 		Value: fmt.Sprintf(
 			"Total Cost: $%.2f\n"+
 				"Total Value: $%.2f\n"+
-				"Total P/L: $%.2f (%.2f%%)",
+				"Unrealized P/L: $%.2f (%.2f%%)\n"+
+				"Realized P/L: $%.2f",
 			totalCost,
 			totalValue,
 			totalPL,
 			totalPLPercent,
+			totalRealized,
 		),
 		Inline: false,
-	})
+	}
 
 	embedColor := 0xFF0000 // Red for loss
-	if totalPL >= 0 {
+	if totalPL+totalRealized >= 0 {
 		embedColor = 0x00FF00 // Green for profit
 	}
-	var description string
-	if portfolioType == "Collective" && len(mentions) > 0 {
-		description = fmt.Sprintf("Hey %s! Check out our collective investments!",
-			strings.Join(mentions, " "))
-	}
 
-	return &discordgo.MessageEmbed{
-		Title:       fmt.Sprintf("%s Portfolio for %s", portfolioType, username),
-		Description: description,
-		Color:       embedColor,
-		Fields:      fields,
+	embed := &discordgo.MessageEmbed{
+		Title:  fmt.Sprintf("%s Portfolio for %s", portfolioType, username),
+		Color:  embedColor,
+		Fields: []*discordgo.MessageEmbedField{summary},
 		Footer: &discordgo.MessageEmbedFooter{
 			Text: time.Now().Format("2006-01-02 15:04:05 MST"),
 		},
 	}
+	return splitFields(embed, fields, holdingsPerPage)
+}
+
+func handleTransactionsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var symbol string
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "symbol" {
+			symbol = parseGeckoID(opt.StringValue())
+		}
+	}
+
+	portMutex.RLock()
+	all := personalTransactionsLocked(i.Member.User.ID)
+	fundNames := make(map[string]string)
+	for _, f := range visibleFundsLocked(i.GuildID, i.Member.User.ID) {
+		for _, tx := range f.Transactions {
+			fundNames[tx.ID] = f.Name
+			all = append(all, tx)
+		}
+	}
+	var txs []*Transaction
+	for _, tx := range all {
+		if symbol == "" || tx.Symbol == symbol {
+			txs = append(txs, tx)
+		}
+	}
+	portMutex.RUnlock()
+	sortTransactions(txs)
+
+	embed := &discordgo.MessageEmbed{
+		Title: "Transaction History",
+		Color: 0x00ff00,
+	}
+	if symbol != "" {
+		embed.Title = fmt.Sprintf("Transaction History for %s", strings.ToUpper(symbol))
+	}
+
+	var lines []string
+	if len(txs) == 0 {
+		embed.Description = "No transactions found"
+	} else {
+		// Most recent transactions first
+		for idx := len(txs) - 1; idx >= 0; idx-- {
+			tx := txs[idx]
+			line := fmt.Sprintf("`%s` **%s** %.4f %s @ $%.2f",
+				tx.Timestamp.Format("2006-01-02"), strings.ToUpper(tx.Side), tx.Amount, strings.ToUpper(tx.Symbol), tx.Price)
+			if tx.Fee > 0 {
+				line += fmt.Sprintf(" (fee $%.2f)", tx.Fee)
+			}
+			if name, ok := fundNames[tx.ID]; ok {
+				line += fmt.Sprintf(" 👥 %s", name)
+			}
+			if tx.Note != "" {
+				line += fmt.Sprintf(" — %s", tx.Note)
+			}
+			lines = append(lines, line)
+		}
+		embed.Footer = &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("%d transactions", len(txs)),
+		}
+	}
+
+	pages := embedPages(splitLines(embed, lines, transactionsPerPage), 1)
+	respondPaginated(s, i, pages, discordgo.MessageFlagsEphemeral)
+}
+
+// removeSymbolTransactions drops every transaction for the coin from a ledger.
+func removeSymbolTransactions(txs []*Transaction, symbol string) ([]*Transaction, bool) {
+	var remaining []*Transaction
+	removed := false
+	for _, tx := range txs {
+		if tx.Symbol == symbol {
+			removed = true
+			continue
+		}
+		remaining = append(remaining, tx)
+	}
+	return remaining, removed
 }
 
 func handleRemoveInvestCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	options := i.ApplicationCommandData().Options
-	symbol := strings.ToLower(options[0].StringValue())
-	userID := i.Member.User.ID
+	symbol := parseGeckoID(options[0].StringValue())
 
+	var fundName string
+	for _, opt := range options[1:] {
+		if opt.Name == "group" {
+			fundName = opt.StringValue()
+		}
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: removeInvestment(i.GuildID, i.Member.User.ID, fundName, symbol),
+		},
+	})
+}
+
+// removeInvestment drops every transaction for the coin from the user's
+// portfolio, or from the named group, and returns the outcome for the user.
+func removeInvestment(guildID, userID, fundName, symbol string) string {
 	portMutex.Lock()
-	portfolio, exists := portfolios[userID]
 	removed := false
-	if exists {
-		if _, hasInvestment := portfolio.Investments[symbol]; hasInvestment {
-			delete(portfolio.Investments, symbol)
-			removed = true
+	var fundID string
+	content := "No investment found for this cryptocurrency"
+	if fundName != "" {
+		// Fund positions are shared, so only group admins may remove them
+		fund := findFundLocked(guildID, userID, fundName)
+		switch {
+		case fund == nil:
+			content = fmt.Sprintf("You're not a member of a group named %q", fundName)
+		case !fund.IsAdmin(userID):
+			content = "Only group admins can remove its investments"
+		default:
+			fundID = fund.ID
+			fund.Transactions, removed = removeSymbolTransactions(fund.Transactions, symbol)
 		}
+	} else if portfolio, exists := portfolios[userID]; exists {
+		portfolio.Transactions, removed = removeSymbolTransactions(portfolio.Transactions, symbol)
 	}
 	portMutex.Unlock()
 
 	if removed {
-		if err := savePortfolios(); err != nil {
-			log.Printf("Error saving portfolios: %v", err)
+		saveLedger(userID, fundID)
+
+		content = fmt.Sprintf("Removed investment in %s", strings.ToUpper(symbol))
+		if fundName != "" {
+			content += fmt.Sprintf(" from group %s", fundName)
 		}
+	}
+	return content
+}
 
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("Removed investment in %s", strings.ToUpper(symbol)),
-			},
-		})
-	} else {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "No investment found for this cryptocurrency",
+func setInvestCommandOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "symbol",
+			Description:  "Cryptocurrency symbol",
+			Required:     false,
+			Autocomplete: true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionNumber,
+			Name:        "amount",
+			Description: "Amount of coins",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionNumber,
+			Name:        "price",
+			Description: "Buy price per coin in USD",
+			Required:    false,
+		},
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "group",
+			Description:  "Buy for a group instead of yourself",
+			Required:     false,
+			Autocomplete: true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionNumber,
+			Name:        "fee",
+			Description: "Trading fee in USD",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "note",
+			Description: "Note for this purchase",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "date",
+			Description: "Purchase date (YYYY-MM-DD), defaults to now",
+			Required:    false,
+		},
+	}
+}
+
+func sellInvestCommandOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "symbol",
+			Description:  "Cryptocurrency symbol",
+			Required:     true,
+			Autocomplete: true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionNumber,
+			Name:        "amount",
+			Description: "Amount of coins sold",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionNumber,
+			Name:        "price",
+			Description: "Sell price per coin in USD",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionNumber,
+			Name:        "fee",
+			Description: "Trading fee in USD",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "note",
+			Description: "Note for this sale",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "date",
+			Description: "Sale date (YYYY-MM-DD), defaults to now",
+			Required:    false,
+		},
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "group",
+			Description:  "Sell from a group you administer",
+			Required:     false,
+			Autocomplete: true,
+		},
+	}
+}
+
+func transactionsCommandOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "symbol",
+			Description:  "Only show transactions for this cryptocurrency",
+			Required:     false,
+			Autocomplete: true,
+		},
+	}
+}
+
+func assetsCommandOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "view",
+			Description: "What to show (default: everything)",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "Personal", Value: "personal"},
+				{Name: "Collective", Value: "collective"},
+				{Name: "Allocation", Value: "allocation"},
 			},
-		})
+		},
+	}
+}
+
+func removeInvestCommandOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "crypto",
+			Description:  "Cryptocurrency to remove",
+			Required:     true,
+			Autocomplete: true,
+		},
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "group",
+			Description:  "Remove from a group you administer",
+			Required:     false,
+			Autocomplete: true,
+		},
 	}
 }
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	investGroupPrefix  = "invest.group"
+	investUsersPrefix  = "invest.users"
+	investOpenPrefix   = "invest.open"
+	investModalPrefix  = "invest.modal"
+	investCancelPrefix = "invest.cancel"
+
+	personalFormValue = "personal" // Group select value for a personal buy
+	maxFormGroups     = maxSelectOptions - 1
+)
+
+// investForm is a guided /setinvest in progress. The form message holds the
+// group and participant selects, the trade itself is entered in a modal.
+type investForm struct {
+	id      string
+	ownerID string
+	guildID string
+	order   investOrder
+	inputs  map[string]string // Last modal inputs, to fill the modal in again
+	err     string            // Shown in the form after a rejected submission
+	expires time.Time
+}
+
+// formRef is the payload of the form's components, the form keeps its own
+// expiry like a paginator.
+type formRef struct {
+	ID string `json:"f"`
+}
+
+var (
+	investForms      = make(map[string]*investForm)
+	investFormsMutex sync.Mutex
+)
+
+// openInvestForm answers /setinvest with the guided form, starting from
+// whatever options were given.
+func openInvestForm(s *discordgo.Session, i *discordgo.InteractionCreate, order investOrder) {
+	f := &investForm{
+		id:      newStateID(),
+		ownerID: i.Member.User.ID,
+		guildID: i.GuildID,
+		order:   order,
+		inputs:  make(map[string]string),
+		expires: time.Now().Add(modalTTL),
+	}
+
+	// Resolve a group given by name, so the select can show it
+	if order.Group != "" {
+		portMutex.RLock()
+		if fund := findFundLocked(i.GuildID, f.ownerID, order.Group); fund != nil {
+			f.order.Group = fund.ID
+		}
+		portMutex.RUnlock()
+	}
+
+	formatNumber := func(value float64) string {
+		if value == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	f.inputs["symbol"] = order.Symbol
+	f.inputs["amount"] = formatNumber(order.Amount)
+	f.inputs["price"] = formatNumber(order.Price)
+	f.inputs["fee"] = formatNumber(order.Fee)
+	f.inputs["date"] = order.Date
+
+	investFormsMutex.Lock()
+	now := time.Now()
+	for id, other := range investForms {
+		if now.After(other.expires) {
+			delete(investForms, id)
+		}
+	}
+	investForms[f.id] = f
+	embeds, components := f.render()
+	investFormsMutex.Unlock()
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     embeds,
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// lockInvestForm returns the form with investFormsMutex held, or responds
+// and returns nil when it's gone.
+func lockInvestForm(s *discordgo.Session, i *discordgo.InteractionCreate, ref formRef) *investForm {
+	investFormsMutex.Lock()
+	f, ok := investForms[ref.ID]
+	if ok && time.Now().After(f.expires) {
+		delete(investForms, ref.ID)
+		ok = false
+	}
+	if !ok || f.ownerID != i.Member.User.ID {
+		investFormsMutex.Unlock()
+		respondComponentError(s, i, "⌛ This form has expired, run `/setinvest` again")
+		return nil
+	}
+	f.expires = time.Now().Add(modalTTL)
+	return f
+}
+
+// updateInvestForm redraws the form message. The caller must hold
+// investFormsMutex, which is released.
+func updateInvestForm(s *discordgo.Session, i *discordgo.InteractionCreate, f *investForm) {
+	embeds, components := f.render()
+	investFormsMutex.Unlock()
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     embeds,
+			Components: components,
+		},
+	})
+}
+
+func (f *investForm) render() ([]*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	ref := formRef{ID: f.id}
+
+	portMutex.RLock()
+	var funds []*Fund
+	for _, fund := range visibleFundsLocked(f.guildID, f.ownerID) {
+		if fund.IsAdmin(f.ownerID) && len(funds) < maxFormGroups {
+			funds = append(funds, fund)
+		}
+	}
+	var groupName string
+	if fund := guildFundLocked(f.guildID, f.order.Group); fund != nil {
+		groupName = fund.Name
+	}
+	portMutex.RUnlock()
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "📝 New Investment",
+		Description: "Choose who the buy is for, then enter the trade details.",
+		Color:       0x3498db,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Buying for", Value: "Yourself", Inline: true},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Power users can pass everything as /setinvest options",
+		},
+	}
+	if groupName != "" {
+		participants := "Every group member"
+		if len(f.order.Participants) > 0 {
+			var mentions []string
+			for _, userID := range f.order.Participants {
+				mentions = append(mentions, fmt.Sprintf("<@%s>", userID))
+			}
+			participants = strings.Join(mentions, " ")
+		}
+		embed.Fields[0].Value = "👥 " + groupName
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Participants",
+			Value:  truncateFieldValue(participants),
+			Inline: true,
+		})
+	}
+	if f.err != "" {
+		embed.Description = "⚠️ " + f.err
+		embed.Color = 0xff0000
+	}
+
+	groupOptions := []discordgo.SelectMenuOption{{
+		Label:   "Myself",
+		Value:   personalFormValue,
+		Default: groupName == "",
+	}}
+	for _, fund := range funds {
+		groupOptions = append(groupOptions, discordgo.SelectMenuOption{
+			Label:   fund.Name,
+			Value:   fund.ID,
+			Default: fund.ID == f.order.Group,
+		})
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					CustomID:    componentID(investGroupPrefix, 0, ref),
+					Placeholder: "Buying for…",
+					Options:     groupOptions,
+				},
+			},
+		},
+	}
+
+	// Participants only apply to group buys
+	if groupName != "" {
+		minValues := 0
+		var defaults []discordgo.SelectMenuDefaultValue
+		for _, userID := range f.order.Participants {
+			defaults = append(defaults, discordgo.SelectMenuDefaultValue{ID: userID, Type: discordgo.SelectMenuDefaultValueUser})
+		}
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:      discordgo.UserSelectMenu,
+					CustomID:      componentID(investUsersPrefix, 0, ref),
+					Placeholder:   "Participants (default: every group member)",
+					MinValues:     &minValues,
+					MaxValues:     maxSelectOptions,
+					DefaultValues: defaults,
+				},
+			},
+		})
+	}
+
+	components = append(components, discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "✏️ Enter details",
+				Style:    discordgo.PrimaryButton,
+				CustomID: componentID(investOpenPrefix, 0, ref),
+			},
+			discordgo.Button{
+				Label:    "Cancel",
+				Style:    discordgo.SecondaryButton,
+				CustomID: componentID(investCancelPrefix, 0, ref),
+			},
+		},
+	})
+	return []*discordgo.MessageEmbed{embed}, components
+}
+
+func handleInvestGroupSelect(s *discordgo.Session, i *discordgo.InteractionCreate, ref formRef) {
+	f := lockInvestForm(s, i, ref)
+	if f == nil {
+		return
+	}
+
+	group := ""
+	if values := i.MessageComponentData().Values; len(values) > 0 && values[0] != personalFormValue {
+		group = values[0]
+	}
+	if group != f.order.Group {
+		f.order.Group = group
+		f.order.Participants = nil
+	}
+	f.err = ""
+	updateInvestForm(s, i, f)
+}
+
+func handleInvestUsersSelect(s *discordgo.Session, i *discordgo.InteractionCreate, ref formRef) {
+	f := lockInvestForm(s, i, ref)
+	if f == nil {
+		return
+	}
+
+	f.order.Participants = append([]string{}, i.MessageComponentData().Values...)
+	f.err = ""
+	updateInvestForm(s, i, f)
+}
+
+func handleInvestCancel(s *discordgo.Session, i *discordgo.InteractionCreate, ref formRef) {
+	f := lockInvestForm(s, i, ref)
+	if f == nil {
+		return
+	}
+	delete(investForms, f.id)
+	investFormsMutex.Unlock()
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    "Cancelled, nothing was recorded.",
+			Embeds:     []*discordgo.MessageEmbed{},
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+}
+
+// handleInvestOpen shows the trade details modal, filled in with the last inputs.
+func handleInvestOpen(s *discordgo.Session, i *discordgo.InteractionCreate, ref formRef) {
+	f := lockInvestForm(s, i, ref)
+	if f == nil {
+		return
+	}
+	inputs := make(map[string]string, len(f.inputs))
+	for name, value := range f.inputs {
+		inputs[name] = value
+	}
+	investFormsMutex.Unlock()
+
+	input := func(name, label, placeholder string, required bool) discordgo.MessageComponent {
+		return discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.TextInput{
+					CustomID:    name,
+					Label:       label,
+					Style:       discordgo.TextInputShort,
+					Placeholder: placeholder,
+					Value:       inputs[name],
+					Required:    required,
+					MaxLength:   50,
+				},
+			},
+		}
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: componentID(investModalPrefix, 0, ref),
+			Title:    "Investment details",
+			Components: []discordgo.MessageComponent{
+				input("symbol", "Cryptocurrency", "e.g. bitcoin or BTC", true),
+				input("amount", "Amount of coins", "e.g. 0.5", true),
+				input("price", "Buy price per coin (USD)", "e.g. 65000", true),
+				input("fee", "Trading fee (USD)", "Optional", false),
+				input("date", "Purchase date (YYYY-MM-DD)", "Optional, defaults to now", false),
+			},
+		},
+	})
+}
+
+// parseInvestInputs fills the order from the modal, returning the first
+// problem found.
+func parseInvestInputs(order *investOrder, inputs map[string]string) error {
+	parseNumber := func(name, label string) (float64, error) {
+		value := strings.TrimPrefix(strings.ReplaceAll(inputs[name], ",", ""), "$")
+		if value == "" {
+			return 0, nil
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s %q isn't a number", label, inputs[name])
+		}
+		return number, nil
+	}
+
+	order.Symbol = strings.ToLower(inputs["symbol"])
+	if order.Symbol == "" {
+		return fmt.Errorf("enter a cryptocurrency")
+	}
+	// Accept tickers as well as CoinGecko IDs
+	if id, ok := geckoIDForSymbol(order.Symbol); ok {
+		order.Symbol = id
+	}
+
+	var err error
+	if order.Amount, err = parseNumber("amount", "Amount"); err != nil {
+		return err
+	}
+	if order.Price, err = parseNumber("price", "Price"); err != nil {
+		return err
+	}
+	if order.Fee, err = parseNumber("fee", "Fee"); err != nil {
+		return err
+	}
+	order.Date = inputs["date"]
+	return nil
+}
+
+func handleInvestModal(s *discordgo.Session, i *discordgo.InteractionCreate, ref formRef) {
+	f := lockInvestForm(s, i, ref)
+	if f == nil {
+		return
+	}
+	f.inputs = modalValues(i)
+
+	order := f.order
+	err := parseInvestInputs(&order, f.inputs)
+	if err != nil {
+		f.err = capitalize(err.Error())
+		updateInvestForm(s, i, f)
+		return
+	}
+	investFormsMutex.Unlock()
+
+	response, public, err := recordInvestment(s, f.guildID, f.ownerID, order)
+	if err != nil {
+		// The form may have expired while the trade was being recorded
+		if f = lockInvestForm(s, i, ref); f != nil {
+			f.err = capitalize(err.Error())
+			updateInvestForm(s, i, f)
+		}
+		return
+	}
+
+	investFormsMutex.Lock()
+	delete(investForms, f.id)
+	investFormsMutex.Unlock()
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    "✅ " + response,
+			Embeds:     []*discordgo.MessageEmbed{},
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+	// Group buys are announced to everyone, like with the slash options
+	if public {
+		s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Content: response,
+		})
+	}
+}
+
+// capitalize upper-cases the first letter of an error for display.
+func capitalize(message string) string {
+	if message == "" {
+		return message
+	}
+	return strings.ToUpper(message[:1]) + message[1:]
+}
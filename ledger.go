@@ -0,0 +1,264 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+)
+
+// Transaction is a single entry in a portfolio's ledger.
+type Transaction struct {
+	ID           string    `json:"id"`
+	Side         string    `json:"side"`   // "buy" or "sell"
+	Symbol       string    `json:"symbol"` // CoinGecko ID
+	Amount       float64   `json:"amount"`
+	Price        float64   `json:"price"` // Price per coin in USD
+	Fee          float64   `json:"fee,omitempty"`
+	Note         string    `json:"note,omitempty"`
+	Type         string    `json:"type"`                   // "personal" or "collective"
+	Participants []string  `json:"participants,omitempty"` // Legacy, collective buys now live in a Fund
+	CreatedBy    string    `json:"created_by"`
+	Timestamp    time.Time `json:"timestamp"`
+
+	// USD paid by each fund member towards a collective buy
+	Contributions map[string]float64 `json:"contributions,omitempty"`
+}
+
+// Holding is the position in one coin derived from a set of transactions.
+type Holding struct {
+	Symbol       string
+	Type         string
+	Amount       float64
+	CostBasis    float64 // Cost of the coins still held, including buy fees
+	RealizedPL   float64
+	Participants []string
+	CreatedBy    string
+	FirstBuy     time.Time
+	TxCount      int
+	FundName     string  // Set for collective holdings
+	Share        float64 // Fraction of the fund's holding, set when scaled to one member
+}
+
+// AvgCost returns the average cost per coin still held.
+func (h *Holding) AvgCost() float64 {
+	if h.Amount == 0 {
+		return 0
+	}
+	return h.CostBasis / h.Amount
+}
+
+func newTransactionID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return fmt.Sprintf("%x-%s", time.Now().UnixNano(), hex.EncodeToString(b))
+}
+
+// sortTransactions orders a ledger chronologically.
+func sortTransactions(txs []*Transaction) {
+	sort.SliceStable(txs, func(i, j int) bool {
+		return txs[i].Timestamp.Before(txs[j].Timestamp)
+	})
+}
+
+// Cost basis methods used to match sells against earlier buys
+const (
+	CostBasisFIFO    = "fifo"
+	CostBasisLIFO    = "lifo"
+	CostBasisAverage = "average"
+)
+
+var costBasisNames = map[string]string{
+	CostBasisFIFO:    "FIFO",
+	CostBasisLIFO:    "LIFO",
+	CostBasisAverage: "Weighted Average",
+}
+
+// Lot is a quantity of coins acquired by one buy that hasn't been sold yet.
+// Under the average method there is a single pooled lot per coin.
+type Lot struct {
+	Symbol      string
+	BuyTxID     string
+	Acquired    time.Time
+	Amount      float64
+	CostPerCoin float64 // Including the buy fee
+}
+
+// RealizedLot is the part of a sell matched against one lot.
+type RealizedLot struct {
+	Symbol    string
+	BuyTxID   string
+	SellTxID  string
+	Acquired  time.Time
+	Sold      time.Time
+	Amount    float64
+	CostBasis float64
+	Proceeds  float64 // Net of the sell fee
+}
+
+func (r RealizedLot) PL() float64 {
+	return r.Proceeds - r.CostBasis
+}
+
+// LedgerReport is the result of replaying a ledger with a cost basis method.
+type LedgerReport struct {
+	Holdings map[string]*Holding
+	OpenLots map[string][]*Lot
+	Realized []RealizedLot
+}
+
+func normalizeCostBasisMethod(method string) string {
+	if _, ok := costBasisNames[method]; ok {
+		return method
+	}
+	return CostBasisAverage
+}
+
+// replayLedger replays transactions in chronological order, matching sells
+// against open lots using the given cost basis method.
+func replayLedger(txs []*Transaction, method string) *LedgerReport {
+	method = normalizeCostBasisMethod(method)
+
+	ordered := make([]*Transaction, len(txs))
+	copy(ordered, txs)
+	sortTransactions(ordered)
+
+	report := &LedgerReport{
+		Holdings: make(map[string]*Holding),
+		OpenLots: make(map[string][]*Lot),
+	}
+
+	for _, tx := range ordered {
+		h, exists := report.Holdings[tx.Symbol]
+		if !exists {
+			h = &Holding{
+				Symbol:    tx.Symbol,
+				Type:      tx.Type,
+				CreatedBy: tx.CreatedBy,
+				FirstBuy:  tx.Timestamp,
+			}
+			report.Holdings[tx.Symbol] = h
+		}
+		h.TxCount++
+		h.Participants = mergeParticipants(h.Participants, tx.Participants)
+
+		switch tx.Side {
+		case SideBuy:
+			if tx.Amount <= 0 {
+				continue
+			}
+			cost := tx.Amount*tx.Price + tx.Fee
+			lots := report.OpenLots[tx.Symbol]
+			if method == CostBasisAverage && len(lots) > 0 {
+				pool := lots[0]
+				pool.CostPerCoin = (pool.CostPerCoin*pool.Amount + cost) / (pool.Amount + tx.Amount)
+				pool.Amount += tx.Amount
+			} else {
+				report.OpenLots[tx.Symbol] = append(lots, &Lot{
+					Symbol:      tx.Symbol,
+					BuyTxID:     tx.ID,
+					Acquired:    tx.Timestamp,
+					Amount:      tx.Amount,
+					CostPerCoin: cost / tx.Amount,
+				})
+			}
+			h.Amount += tx.Amount
+			h.CostBasis += cost
+		case SideSell:
+			remaining := math.Min(tx.Amount, h.Amount)
+			if remaining <= 0 {
+				continue
+			}
+			sold := remaining
+
+			lots := report.OpenLots[tx.Symbol]
+			for remaining > 1e-12 && len(lots) > 0 {
+				// FIFO and average consume from the front, LIFO from the back
+				idx := 0
+				if method == CostBasisLIFO {
+					idx = len(lots) - 1
+				}
+				lot := lots[idx]
+
+				matched := math.Min(remaining, lot.Amount)
+				realized := RealizedLot{
+					Symbol:    tx.Symbol,
+					BuyTxID:   lot.BuyTxID,
+					SellTxID:  tx.ID,
+					Acquired:  lot.Acquired,
+					Sold:      tx.Timestamp,
+					Amount:    matched,
+					CostBasis: matched * lot.CostPerCoin,
+					Proceeds:  matched*tx.Price - tx.Fee*matched/sold,
+				}
+				report.Realized = append(report.Realized, realized)
+
+				h.RealizedPL += realized.PL()
+				h.CostBasis -= realized.CostBasis
+				h.Amount -= matched
+				lot.Amount -= matched
+				remaining -= matched
+
+				if lot.Amount <= 1e-12 {
+					lots = append(lots[:idx], lots[idx+1:]...)
+				}
+			}
+			report.OpenLots[tx.Symbol] = lots
+		}
+	}
+	return report
+}
+
+// computeHoldings returns the position per coin using the average cost method.
+func computeHoldings(txs []*Transaction) map[string]*Holding {
+	return replayLedger(txs, CostBasisAverage).Holdings
+}
+
+func mergeParticipants(existing, added []string) []string {
+	for _, id := range added {
+		found := false
+		for _, e := range existing {
+			if e == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, id)
+		}
+	}
+	return existing
+}
+
+// oversoldSell replays the transactions for symbol in order and returns the
+// first sell of more coins than were held at its time, with the amount held.
+func oversoldSell(txs []*Transaction, symbol string) (*Transaction, float64) {
+	ordered := make([]*Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if tx.Symbol == symbol {
+			ordered = append(ordered, tx)
+		}
+	}
+	sortTransactions(ordered)
+
+	var held float64
+	for _, tx := range ordered {
+		switch tx.Side {
+		case SideBuy:
+			held += tx.Amount
+		case SideSell:
+			if tx.Amount > held+1e-9 {
+				return tx, held
+			}
+			held -= tx.Amount
+		}
+	}
+	return nil, 0
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOversoldSell(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	buy := func(amount float64, at time.Time) *Transaction {
+		return &Transaction{ID: newTransactionID(), Side: SideBuy, Symbol: "bitcoin", Amount: amount, Price: 100, Timestamp: at}
+	}
+	sell := func(amount float64, at time.Time) *Transaction {
+		return &Transaction{ID: newTransactionID(), Side: SideSell, Symbol: "bitcoin", Amount: amount, Price: 120, Timestamp: at}
+	}
+
+	tests := []struct {
+		name     string
+		txs      []*Transaction
+		oversold int // Index of the expected oversold sell, -1 for none
+		held     float64
+	}{
+		{"same-day buy then sell", []*Transaction{buy(1, day), sell(1, day)}, -1, 0},
+		{"sell dated before the buy", []*Transaction{buy(1, day), sell(1, day.AddDate(0, 0, -1))}, 1, 0},
+		{"sell more than held", []*Transaction{buy(1, day), sell(1.5, day.Add(time.Hour))}, 1, 1},
+		{"earlier sell uncovers a later one", []*Transaction{buy(1, day), sell(1, day.AddDate(0, 0, 2)), sell(0.5, day.AddDate(0, 0, 1))}, 1, 0.5},
+		{"other coins don't count", []*Transaction{{Side: SideBuy, Symbol: "ethereum", Amount: 5, Timestamp: day}, sell(1, day)}, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := append([]*Transaction{}, tt.txs...)
+			sortTransactions(ledger)
+			got, held := oversoldSell(ledger, "bitcoin")
+			if tt.oversold < 0 {
+				if got != nil {
+					t.Fatalf("unexpected oversold sell of %v on %s", got.Amount, got.Timestamp)
+				}
+				return
+			}
+			if got != tt.txs[tt.oversold] {
+				t.Fatalf("oversold = %+v, want transaction %d", got, tt.oversold)
+			}
+			if held != tt.held {
+				t.Errorf("held = %v, want %v", held, tt.held)
+			}
+		})
+	}
+}
+
+func TestSameDaySellIsReplayed(t *testing.T) {
+	// A buy recorded without a date and a sell dated today
+	bought := time.Now()
+	today, err := parseTransactionDate(bought.UTC().Format("2006-01-02"))
+	if err != nil {
+		t.Fatalf("parseTransactionDate: %v", err)
+	}
+	if today.Before(bought) {
+		t.Fatalf("today's date parsed to %s, before the buy at %s", today, bought)
+	}
+
+	ledger := []*Transaction{
+		{ID: "buy", Side: SideBuy, Symbol: "bitcoin", Amount: 2, Price: 100, Timestamp: bought},
+		{ID: "sell", Side: SideSell, Symbol: "bitcoin", Amount: 1.5, Price: 150, Timestamp: today},
+	}
+	sortTransactions(ledger)
+	if oversold, _ := oversoldSell(ledger, "bitcoin"); oversold != nil {
+		t.Fatalf("sell was not covered by the same-day buy")
+	}
+
+	report := replayLedger(ledger, CostBasisFIFO)
+	if h := report.Holdings["bitcoin"]; h.Amount != 0.5 || h.RealizedPL != 75 {
+		t.Errorf("holding = %.2f coins, %.2f realized; want 0.50 coins, 75.00 realized", h.Amount, h.RealizedPL)
+	}
+	if len(report.Realized) != 1 {
+		t.Errorf("realized %d lots, want 1", len(report.Realized))
+	}
+}
+
+func TestFundSharesPerCoin(t *testing.T) {
+	at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	fund := &Fund{
+		CreatedBy: "alice",
+		Members:   []string{"alice", "bob"},
+		Transactions: []*Transaction{
+			{Side: SideBuy, Symbol: "bitcoin", Amount: 1, Price: 1000, Timestamp: at,
+				Contributions: map[string]float64{"alice": 1000}},
+			{Side: SideBuy, Symbol: "ethereum", Amount: 2, Price: 100, Timestamp: at,
+				Contributions: splitContribution(200, []string{"alice", "bob"})},
+		},
+	}
+
+	tests := []struct {
+		userID, symbol string
+		want           float64
+	}{
+		{"alice", "bitcoin", 1},
+		{"bob", "bitcoin", 0},
+		{"alice", "ethereum", 0.5},
+		{"bob", "ethereum", 0.5},
+		{"bob", "solana", 0},
+	}
+	for _, tt := range tests {
+		if got := fund.Share(tt.userID, tt.symbol); got != tt.want {
+			t.Errorf("Share(%s, %s) = %v, want %v", tt.userID, tt.symbol, got, tt.want)
+		}
+	}
+
+	positions := fund.Positions()
+	if positions[0].UserID != "alice" || positions[0].Contribution != 1100 {
+		t.Errorf("first position = %+v, want alice with 1100 in", positions[0])
+	}
+	if bob := positions[1]; bob.Contribution != 100 || bob.Shares["bitcoin"] != 0 || bob.Shares["ethereum"] != 0.5 {
+		t.Errorf("bob's position = %+v", bob)
+	}
+	if !fund.Contributed("bob") || fund.Contributed("carol") {
+		t.Error("Contributed doesn't follow the buys")
+	}
+	if fund.inGuild("guild") {
+		t.Error("a fund without a guild should be hidden in guilds")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/signal"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -20,10 +21,16 @@ type CryptoPrice struct {
 	Change24h float64 `json:"usd_24h_change"`
 	MarketCap float64 `json:"usd_market_cap"`
 	Volume24h float64 `json:"usd_24h_vol"`
+
+	// Set in consensus mode
+	Sources      int    `json:"-"` // Number of providers that answered
+	Disputed     bool   `json:"-"` // Sources deviated beyond priceDeviationThreshold
+	SingleSource string `json:"-"` // The only provider that answered, so nothing was cross-checked
 }
 
 type PriceBot struct {
 	Token      string
+	ClientID   string
 	Symbol     string
 	GuildID    string
 	Session    *discordgo.Session
@@ -51,16 +58,30 @@ func init() {
 		}
 	}
 
-	// Changed from loadInvestments to loadPortfolios
-	if err := loadPortfolios(); err != nil {
-		log.Printf("Error loading portfolios: %v", err)
-	}
-
 	// Get environment variables directly
 	tokens := strings.Split(os.Getenv("BOT_TOKENS"), ",")
 	clientIDs := strings.Split(os.Getenv("BOT_CLIENT_IDS"), ",")
 	COINMARKETCAP_API_KEY = os.Getenv("COINMARKETCAP_API_KEY")
 	SANTIMENT_API_KEY = os.Getenv("SANTIMENT_API_KEY")
+	devGuildID = os.Getenv("DEV_GUILD_ID")
+	for _, id := range strings.Split(os.Getenv("OWNER_IDS"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ownerIDs[id] = true
+		}
+	}
+	if dir := os.Getenv("BACKUP_DIR"); dir != "" {
+		backupDir = dir
+	}
+	santimentClient = newSantimentClient(SANTIMENT_API_KEY)
+
+	priceConsensusMode, _ = strconv.ParseBool(os.Getenv("PRICE_CONSENSUS"))
+	if threshold := os.Getenv("PRICE_DEVIATION_THRESHOLD"); threshold != "" {
+		if v, err := strconv.ParseFloat(threshold, 64); err == nil && v > 0 {
+			priceDeviationThreshold = v
+		} else {
+			log.Printf("Warning: invalid PRICE_DEVIATION_THRESHOLD %q, using %.1f%%", threshold, priceDeviationThreshold)
+		}
+	}
 
 	if len(tokens) != len(clientIDs) {
 		log.Fatal("Number of tokens and client IDs must match")
@@ -75,6 +96,18 @@ func init() {
 }
 
 func main() {
+	if err := openStore(); err != nil {
+		log.Fatal("Error opening store:", err)
+	}
+	defer store.Close()
+
+	savedBots := make(map[string]priceBotRecord)
+	if st, err := loadState(); err != nil {
+		log.Printf("Error loading bot state: %v", err)
+	} else {
+		savedBots = st.PriceBots
+	}
+
 	discord, err := discordgo.New("Bot " + os.Getenv("DISCORD_TOKEN"))
 	if err != nil {
 		log.Fatal("Error creating Discord session:", err)
@@ -84,7 +117,7 @@ func main() {
 
 	discord.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
 		log.Printf("Bot is ready: %v#%v", s.State.User.Username, s.State.User.Discriminator)
-		registerCommands(s)
+		syncCommands(s, r.Guilds)
 	})
 
 	discord.AddHandler(interactionHandler)
@@ -96,8 +129,15 @@ func main() {
 		return
 	}
 
+	// Reconnect the price bots that were running before the restart
+	go restorePriceBots(savedBots)
+
 	// Start price update routine
 	go updatePrices()
+	go runFearGreedScheduler(discord)
+	go runSnapshotScheduler()
+	go runTickerScheduler(discord)
+	go runDigestScheduler(discord)
 
 	fmt.Println("Bot is running. Press Ctrl+C to exit.")
 	sc := make(chan os.Signal, 1)
@@ -108,236 +148,24 @@ func main() {
 	discord.Close()
 }
 
-func registerCommands(s *discordgo.Session) {
-	commands := []*discordgo.ApplicationCommand{
-		{
-			Name:        "price",
-			Description: "Get cryptocurrency price information",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:         discordgo.ApplicationCommandOptionString,
-					Name:         "crypto",
-					Description:  "Cryptocurrency name (e.g., bitcoin, ethereum)",
-					Required:     true,
-					Autocomplete: true,
-				},
-			},
-		},
-		{
-			Name:        "add",
-			Description: "Add a new price display bot",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:         discordgo.ApplicationCommandOptionString,
-					Name:         "crypto",
-					Description:  "Cryptocurrency to track (e.g., bitcoin)",
-					Required:     true,
-					Autocomplete: true,
-				},
-			},
-		},
-		{
-			Name:        "remove",
-			Description: "Remove a price display bot",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "crypto",
-					Description: "Cryptocurrency to stop tracking",
-					Required:    true,
-				},
-			},
-		},
-		{
-			Name:        "help",
-			Description: "Show available commands",
-		},
-		{
-			Name:        "invite",
-			Description: "Get invite links for available price bots",
-		},
-		{
-			Name:        "restart-bot",
-			Description: "Restart all price bots",
-		},
-		{
-			Name:        "clear-bot",
-			Description: "Remove all price bots",
-		},
-		{
-			Name:        "setalert",
-			Description: "Set price alert for a cryptocurrency",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:         discordgo.ApplicationCommandOptionString,
-					Name:         "crypto",
-					Description:  "Cryptocurrency name (e.g., bitcoin, ethereum)",
-					Required:     true,
-					Autocomplete: true, // Enable autocomplete
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionNumber,
-					Name:        "upper",
-					Description: "Alert when price goes above this value (e.g., 50000)",
-					Required:    false,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionNumber,
-					Name:        "lower",
-					Description: "Alert when price goes below this value (e.g., 40000)",
-					Required:    false,
-				},
-			},
-		},
-		{
-			Name:        "removealert",
-			Description: "Remove price alert for a cryptocurrency",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:         discordgo.ApplicationCommandOptionString,
-					Name:         "crypto",
-					Description:  "Cryptocurrency to remove alert for",
-					Required:     true,
-					Autocomplete: true,
-				},
-			},
-		},
-		{
-			Name:        "listalerts",
-			Description: "List all active price alerts",
-		},
-		{
-			Name:        "setinvest",
-			Description: "Set an investment",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:         discordgo.ApplicationCommandOptionString,
-					Name:         "symbol",
-					Description:  "Cryptocurrency symbol",
-					Required:     true,
-					Autocomplete: true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionNumber,
-					Name:        "amount",
-					Description: "Amount of coins",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionNumber,
-					Name:        "price",
-					Description: "Buy price per coin in USD",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "type",
-					Description: "Investment type (personal/collective)",
-					Required:    false,
-					Choices: []*discordgo.ApplicationCommandOptionChoice{
-						{
-							Name:  "Personal",
-							Value: "personal",
-						},
-						{
-							Name:  "Collective",
-							Value: "collective",
-						},
-					},
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "participants",
-					Description: "Mention participants for collective investment (required for collective)",
-					Required:    false,
-				},
-			},
-		},
-
-		{
-			Name:        "assets",
-			Description: "Check all assets value",
-		},
-		{
-			Name:        "removeinvest",
-			Description: "Remove an investment",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:         discordgo.ApplicationCommandOptionString,
-					Name:         "crypto",
-					Description:  "Cryptocurrency to remove",
-					Required:     true,
-					Autocomplete: true,
-				},
-			},
-		},
-	}
-
-	for _, cmd := range commands {
-		_, err := s.ApplicationCommandCreate(s.State.User.ID, "", cmd)
-		if err != nil {
-			log.Printf("Error creating command %v: %v", cmd.Name, err)
-		}
-	}
-}
-
 func interactionHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	switch i.Type {
-	case discordgo.InteractionApplicationCommand:
-		handleSlashCommand(s, i)
-	case discordgo.InteractionApplicationCommandAutocomplete:
-		handleAutocomplete(s, i)
+	// Handlers expect a guild member, DMs are answered by handleSlashCommand only
+	if i.Member == nil && i.Type != discordgo.InteractionApplicationCommand {
+		return
 	}
-}
 
-func handleSlashCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	switch i.Type {
 	case discordgo.InteractionApplicationCommand:
-		switch i.ApplicationCommandData().Name {
-		case "price":
-			handlePriceCommand(s, i)
-		case "add":
-			handleAddCommand(s, i)
-		case "remove":
-			handleRemoveCommand(s, i)
-		case "help":
-			handleHelpCommand(s, i)
-		case "invite":
-			handleInviteCommand(s, i)
-		case "setalert": // Add this case
-			handleSetAlert(s, i)
-		case "removealert": // Add this case
-			handleRemoveAlert(s, i)
-		case "listalerts": // Add this case
-			handleListAlerts(s, i)
-		case "setinvest":
-			handleSetInvestCommand(s, i)
-		case "assets":
-			handleAssetsCommand(s, i)
-		case "removeinvest":
-			handleRemoveInvestCommand(s, i)
-		case "restart-bot":
-			handleRestartCommand(s, i)
-		case "clear-bot":
-			handleClearCommand(s, i)
-		}
-
+		handleSlashCommand(s, i)
 	case discordgo.InteractionApplicationCommandAutocomplete:
 		handleAutocomplete(s, i)
+	case discordgo.InteractionMessageComponent, discordgo.InteractionModalSubmit:
+		handleComponent(s, i)
 	}
 }
 
-func handleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	if i.ApplicationCommandData().Name != "price" &&
-		i.ApplicationCommandData().Name != "add" &&
-		i.ApplicationCommandData().Name != "setinvest" &&
-		i.ApplicationCommandData().Name != "removeinvest" &&
-		i.ApplicationCommandData().Name != "setalert" {
-		return
-	}
-
-	input := strings.ToLower(i.ApplicationCommandData().Options[0].StringValue())
-
+// handleCryptoAutocomplete suggests the coins in commonCryptos.
+func handleCryptoAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, input string) {
 	// Debug log to see what we're searching with
 	log.Printf("Searching for: %s", input)
 
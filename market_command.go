@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	moversUniverse = 100 // Movers are picked from the top coins by market cap
+	moversShown    = 10
+)
+
+var moversPeriods = map[string]string{
+	"1h":  "1 Hour",
+	"24h": "24 Hours",
+	"7d":  "7 Days",
+}
+
+func moverChange(market *coinMarket, period string) float64 {
+	switch period {
+	case "1h":
+		return market.Change1h
+	case "7d":
+		return market.Change7d
+	default:
+		return market.Change24h
+	}
+}
+
+func handleMoversCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	period := "24h"
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "period" {
+			period = opt.StringValue()
+		}
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+
+	markets, err := getTopMarkets(moversUniverse)
+	if err != nil {
+		content := fmt.Sprintf("❌ Error fetching market data: %v", err)
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+		return
+	}
+
+	embed := createMoversEmbed(markets, period)
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+}
+
+// sortedMovers returns the markets sorted by their change over period, best first.
+func sortedMovers(markets []*coinMarket, period string) []*coinMarket {
+	sorted := make([]*coinMarket, len(markets))
+	copy(sorted, markets)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return moverChange(sorted[a], period) > moverChange(sorted[b], period)
+	})
+	return sorted
+}
+
+func formatMoverLines(markets []*coinMarket, period string) string {
+	if len(markets) == 0 {
+		return "None"
+	}
+	var lines []string
+	for _, m := range markets {
+		lines = append(lines, fmt.Sprintf("**%s** %s `%+.2f%%`",
+			strings.ToUpper(m.Symbol), formatUSD(m.CurrentPrice), moverChange(m, period)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func createMoversEmbed(markets []*coinMarket, period string) *discordgo.MessageEmbed {
+	sorted := sortedMovers(markets, period)
+
+	var gainers, losers []*coinMarket
+	for _, m := range sorted {
+		if moverChange(m, period) > 0 && len(gainers) < moversShown {
+			gainers = append(gainers, m)
+		}
+	}
+	for idx := len(sorted) - 1; idx >= 0 && len(losers) < moversShown; idx-- {
+		if moverChange(sorted[idx], period) < 0 {
+			losers = append(losers, sorted[idx])
+		}
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("🔥 Top Movers (%s)", moversPeriods[period]),
+		Color: 0x3498db,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "🚀 Gainers",
+				Value:  formatMoverLines(gainers, period),
+				Inline: true,
+			},
+			{
+				Name:   "📉 Losers",
+				Value:  formatMoverLines(losers, period),
+				Inline: true,
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Among the top %d coins by market cap", len(markets)),
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+}
+
+// changeIndicator formats a percent change with a direction arrow.
+func changeIndicator(change float64) string {
+	switch {
+	case math.IsNaN(change):
+		return ""
+	case change > 0:
+		return fmt.Sprintf("🟢 ▲ %.2f%%", change)
+	case change < 0:
+		return fmt.Sprintf("🔴 ▼ %.2f%%", -change)
+	default:
+		return "⚪ 0.00%"
+	}
+}
+
+func handleMarketCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+
+	global, err := getGlobalMarket()
+	if err != nil {
+		content := fmt.Sprintf("❌ Error fetching market overview: %v", err)
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+		return
+	}
+
+	embed := createMarketEmbed(global)
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+}
+
+func createMarketEmbed(global *globalMarket) *discordgo.MessageEmbed {
+	volume := formatLargeUSD(global.TotalVolume)
+	if indicator := changeIndicator(global.VolumeChange24h); indicator != "" {
+		volume += "\n" + indicator
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "🌍 Crypto Market Overview",
+		Color: getColorForChange(global.MarketCapChange24h),
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "💰 Total Market Cap",
+				Value:  fmt.Sprintf("%s\n%s", formatLargeUSD(global.TotalMarketCap), changeIndicator(global.MarketCapChange24h)),
+				Inline: true,
+			},
+			{
+				Name:   "📊 24h Volume",
+				Value:  volume,
+				Inline: true,
+			},
+			{
+				// Blank field to start a new row
+				Name:   "\u200b",
+				Value:  "\u200b",
+				Inline: true,
+			},
+			{
+				Name:   "₿ BTC Dominance",
+				Value:  fmt.Sprintf("%.2f%%", global.BTCDominance),
+				Inline: true,
+			},
+			{
+				Name:   "Ξ ETH Dominance",
+				Value:  fmt.Sprintf("%.2f%%", global.ETHDominance),
+				Inline: true,
+			},
+			{
+				Name:   "🪙 Active Coins",
+				Value:  fmt.Sprintf("%d", global.ActiveCoins),
+				Inline: true,
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Data from %s • changes over 24h", global.Source),
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	return embed
+}
+
+func moversCommandOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "period",
+			Description: "Time window (default 24 hours)",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "1 hour", Value: "1h"},
+				{Name: "24 hours", Value: "24h"},
+				{Name: "7 days", Value: "7d"},
+			},
+		},
+	}
+}
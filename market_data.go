@@ -0,0 +1,443 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	marketCacheTTL    = time.Minute
+	maxMarketsPerCall = 250 // CoinGecko's page size limit
+)
+
+// coinMarket is one row of CoinGecko's /coins/markets, used by the commands
+// that show many coins at once.
+type coinMarket struct {
+	ID            string  `json:"id"`
+	Symbol        string  `json:"symbol"`
+	Name          string  `json:"name"`
+	CurrentPrice  float64 `json:"current_price"`
+	MarketCap     float64 `json:"market_cap"`
+	MarketCapRank int     `json:"market_cap_rank"`
+	TotalVolume   float64 `json:"total_volume"`
+	Change1h      float64 `json:"price_change_percentage_1h_in_currency"`
+	Change24h     float64 `json:"price_change_percentage_24h"`
+	Change7d      float64 `json:"price_change_percentage_7d_in_currency"`
+	Sparkline     struct {
+		Price []float64 `json:"price"`
+	} `json:"sparkline_in_7d"`
+}
+
+type cachedMarket struct {
+	market    *coinMarket
+	fetchedAt time.Time
+}
+
+var (
+	marketCache      = make(map[string]cachedMarket)
+	marketCacheMutex sync.Mutex
+)
+
+func fetchCoinMarkets(query url.Values) ([]*coinMarket, error) {
+	query.Set("vs_currency", "usd")
+	query.Set("price_change_percentage", "1h,24h,7d")
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Get("https://api.coingecko.com/api/v3/coins/markets?" + query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 429 {
+		return nil, fmt.Errorf("rate limit exceeded")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CoinGecko returned status %d", resp.StatusCode)
+	}
+
+	var markets []*coinMarket
+	if err := json.NewDecoder(resp.Body).Decode(&markets); err != nil {
+		return nil, err
+	}
+	return markets, nil
+}
+
+// getCoinMarkets returns market data for the given CoinGecko IDs, fetching
+// everything that isn't cached in one batched CoinGecko call. When CoinGecko
+// is unavailable it falls back to getCryptoPrice per coin, without sparklines.
+// Coins that can't be found are missing from the result.
+func getCoinMarkets(ids []string) (map[string]*coinMarket, error) {
+	result := make(map[string]*coinMarket)
+	var missing []string
+	queued := make(map[string]bool)
+
+	marketCacheMutex.Lock()
+	for _, id := range ids {
+		if cached, ok := marketCache[id]; ok && time.Since(cached.fetchedAt) < marketCacheTTL {
+			result[id] = cached.market
+		} else if !queued[id] {
+			queued[id] = true
+			missing = append(missing, id)
+		}
+	}
+	marketCacheMutex.Unlock()
+
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	var lastErr error
+	for start := 0; start < len(missing); start += maxMarketsPerCall {
+		end := start + maxMarketsPerCall
+		if end > len(missing) {
+			end = len(missing)
+		}
+		batch := missing[start:end]
+
+		markets, err := fetchTracked(coinGeckoMarketsProvider, func() ([]*coinMarket, error) {
+			query := url.Values{}
+			query.Set("ids", strings.Join(batch, ","))
+			query.Set("sparkline", "true")
+			query.Set("per_page", fmt.Sprint(maxMarketsPerCall))
+			return fetchCoinMarkets(query)
+		})
+		if err != nil {
+			log.Printf("Error fetching markets, falling back to single prices: %v", err)
+			markets, lastErr = fallbackCoinMarkets(batch)
+		}
+
+		now := time.Now()
+		marketCacheMutex.Lock()
+		for _, market := range markets {
+			result[market.ID] = market
+			marketCache[market.ID] = cachedMarket{market: market, fetchedAt: now}
+		}
+		marketCacheMutex.Unlock()
+	}
+
+	if len(result) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return result, nil
+}
+
+// fallbackCoinMarkets builds market rows from the regular price fallback chain.
+func fallbackCoinMarkets(ids []string) ([]*coinMarket, error) {
+	var (
+		markets []*coinMarket
+		lastErr error
+	)
+	for _, id := range ids {
+		price, err := getCryptoPrice(id)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		info := resolveCryptoInfo(id)
+		markets = append(markets, &coinMarket{
+			ID:           id,
+			Symbol:       strings.ToLower(info.Symbol),
+			CurrentPrice: price.Price,
+			MarketCap:    price.MarketCap,
+			TotalVolume:  price.Volume24h,
+			Change24h:    price.Change24h,
+		})
+	}
+	return markets, lastErr
+}
+
+// downsample picks n evenly spaced values, keeping the last one so sparklines
+// end at the current price.
+func downsample(values []float64, n int) []float64 {
+	if len(values) <= n || n < 2 {
+		return values
+	}
+	out := make([]float64, n)
+	for idx := range out {
+		out[idx] = values[idx*(len(values)-1)/(n-1)]
+	}
+	return out
+}
+
+// CoinMarketCap /v1/cryptocurrency/listings/latest
+type cmcListingsResponse struct {
+	Data []struct {
+		Name    string `json:"name"`
+		Symbol  string `json:"symbol"`
+		Slug    string `json:"slug"`
+		CMCRank int    `json:"cmc_rank"`
+		Quote   struct {
+			USD struct {
+				Price            float64 `json:"price"`
+				Volume24H        float64 `json:"volume_24h"`
+				MarketCap        float64 `json:"market_cap"`
+				PercentChange1H  float64 `json:"percent_change_1h"`
+				PercentChange24H float64 `json:"percent_change_24h"`
+				PercentChange7D  float64 `json:"percent_change_7d"`
+			} `json:"USD"`
+		} `json:"quote"`
+	} `json:"data"`
+}
+
+// getCMCJSON fetches a CoinMarketCap Pro API endpoint into v.
+func getCMCJSON(path string, query url.Values, v interface{}) error {
+	req, err := http.NewRequest("GET", "https://pro-api.coinmarketcap.com"+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-CMC_PRO_API_KEY", COINMARKETCAP_API_KEY)
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 429 {
+		return fmt.Errorf("rate limit exceeded")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("CoinMarketCap returned status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func getTopMarketsFromCMC(limit int) ([]*coinMarket, error) {
+	query := url.Values{}
+	query.Set("limit", fmt.Sprint(limit))
+	query.Set("convert", "USD")
+
+	var listings cmcListingsResponse
+	if err := getCMCJSON("/v1/cryptocurrency/listings/latest", query, &listings); err != nil {
+		return nil, err
+	}
+
+	var markets []*coinMarket
+	for _, d := range listings.Data {
+		markets = append(markets, &coinMarket{
+			ID:            d.Slug,
+			Symbol:        strings.ToLower(d.Symbol),
+			Name:          d.Name,
+			CurrentPrice:  d.Quote.USD.Price,
+			MarketCap:     d.Quote.USD.MarketCap,
+			MarketCapRank: d.CMCRank,
+			TotalVolume:   d.Quote.USD.Volume24H,
+			Change1h:      d.Quote.USD.PercentChange1H,
+			Change24h:     d.Quote.USD.PercentChange24H,
+			Change7d:      d.Quote.USD.PercentChange7D,
+		})
+	}
+	return markets, nil
+}
+
+// getTopMarkets returns the largest coins by market cap, from CoinGecko or
+// CoinMarketCap.
+func getTopMarkets(limit int) ([]*coinMarket, error) {
+	return withCache(fmt.Sprintf("markets:top:%d", limit), marketCacheTTL, func() ([]*coinMarket, error) {
+		return fetchWithFallback(map[string]func() ([]*coinMarket, error){
+			"CoinGecko": func() ([]*coinMarket, error) {
+				query := url.Values{}
+				query.Set("order", "market_cap_desc")
+				query.Set("per_page", fmt.Sprint(limit))
+				query.Set("page", "1")
+				return fetchCoinMarkets(query)
+			},
+			"CoinMarketCap": func() ([]*coinMarket, error) {
+				return getTopMarketsFromCMC(limit)
+			},
+		})
+	})
+}
+
+// globalMarket is the whole crypto market. VolumeChange24h is only reported
+// by CoinMarketCap and is NaN otherwise.
+type globalMarket struct {
+	TotalMarketCap     float64
+	TotalVolume        float64
+	BTCDominance       float64
+	ETHDominance       float64
+	MarketCapChange24h float64
+	VolumeChange24h    float64
+	ActiveCoins        int
+	Source             string
+}
+
+func getGlobalFromCoinGecko() (*globalMarket, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Get("https://api.coingecko.com/api/v3/global")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 429 {
+		return nil, fmt.Errorf("rate limit exceeded")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CoinGecko returned status %d", resp.StatusCode)
+	}
+
+	var data struct {
+		Data struct {
+			ActiveCryptocurrencies int                `json:"active_cryptocurrencies"`
+			TotalMarketCap         map[string]float64 `json:"total_market_cap"`
+			TotalVolume            map[string]float64 `json:"total_volume"`
+			MarketCapPercentage    map[string]float64 `json:"market_cap_percentage"`
+			MarketCapChange24h     float64            `json:"market_cap_change_percentage_24h_usd"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, err
+	}
+
+	return &globalMarket{
+		TotalMarketCap:     data.Data.TotalMarketCap["usd"],
+		TotalVolume:        data.Data.TotalVolume["usd"],
+		BTCDominance:       data.Data.MarketCapPercentage["btc"],
+		ETHDominance:       data.Data.MarketCapPercentage["eth"],
+		MarketCapChange24h: data.Data.MarketCapChange24h,
+		VolumeChange24h:    math.NaN(),
+		ActiveCoins:        data.Data.ActiveCryptocurrencies,
+		Source:             "CoinGecko",
+	}, nil
+}
+
+func getGlobalFromCMC() (*globalMarket, error) {
+	var data struct {
+		Data struct {
+			ActiveCryptocurrencies int     `json:"active_cryptocurrencies"`
+			BTCDominance           float64 `json:"btc_dominance"`
+			ETHDominance           float64 `json:"eth_dominance"`
+			Quote                  struct {
+				USD struct {
+					TotalMarketCap       float64 `json:"total_market_cap"`
+					TotalVolume24H       float64 `json:"total_volume_24h"`
+					MarketCapChange24H   float64 `json:"total_market_cap_yesterday_percentage_change"`
+					TotalVolumeChange24H float64 `json:"total_volume_24h_yesterday_percentage_change"`
+				} `json:"USD"`
+			} `json:"quote"`
+		} `json:"data"`
+	}
+	if err := getCMCJSON("/v1/global-metrics/quotes/latest", url.Values{}, &data); err != nil {
+		return nil, err
+	}
+
+	return &globalMarket{
+		TotalMarketCap:     data.Data.Quote.USD.TotalMarketCap,
+		TotalVolume:        data.Data.Quote.USD.TotalVolume24H,
+		BTCDominance:       data.Data.BTCDominance,
+		ETHDominance:       data.Data.ETHDominance,
+		MarketCapChange24h: data.Data.Quote.USD.MarketCapChange24H,
+		VolumeChange24h:    data.Data.Quote.USD.TotalVolumeChange24H,
+		ActiveCoins:        data.Data.ActiveCryptocurrencies,
+		Source:             "CoinMarketCap",
+	}, nil
+}
+
+// getGlobalMarket returns total market figures, from CoinGecko's /global or
+// CoinMarketCap's global metrics.
+func getGlobalMarket() (*globalMarket, error) {
+	return withCache("global", marketCacheTTL, func() (*globalMarket, error) {
+		return fetchWithFallback(map[string]func() (*globalMarket, error){
+			"CoinGecko":     getGlobalFromCoinGecko,
+			"CoinMarketCap": getGlobalFromCMC,
+		})
+	})
+}
+
+const coinDetailCacheTTL = 5 * time.Minute
+
+// coinDetail is the subset of CoinGecko's /coins/{id} shown by /coin.
+// Supplies are nil when CoinGecko doesn't know them, a nil max supply
+// usually means the supply is uncapped.
+type coinDetail struct {
+	ID               string            `json:"id"`
+	Symbol           string            `json:"symbol"`
+	Name             string            `json:"name"`
+	MarketCapRank    int               `json:"market_cap_rank"`
+	Categories       []string          `json:"categories"`
+	Platforms        map[string]string `json:"platforms"` // Platform to contract address
+	HashingAlgorithm string            `json:"hashing_algorithm"`
+	GenesisDate      string            `json:"genesis_date"`
+	Links            struct {
+		Homepage       []string `json:"homepage"`
+		BlockchainSite []string `json:"blockchain_site"`
+	} `json:"links"`
+	Image struct {
+		Large string `json:"large"`
+	} `json:"image"`
+	MarketData struct {
+		CurrentPrice        map[string]float64 `json:"current_price"`
+		MarketCap           map[string]float64 `json:"market_cap"`
+		FullyDilutedValue   map[string]float64 `json:"fully_diluted_valuation"`
+		TotalVolume         map[string]float64 `json:"total_volume"`
+		High24h             map[string]float64 `json:"high_24h"`
+		Low24h              map[string]float64 `json:"low_24h"`
+		Change24h           float64            `json:"price_change_percentage_24h"`
+		ATH                 map[string]float64 `json:"ath"`
+		ATHChangePercentage map[string]float64 `json:"ath_change_percentage"`
+		ATHDate             map[string]string  `json:"ath_date"`
+		ATL                 map[string]float64 `json:"atl"`
+		ATLChangePercentage map[string]float64 `json:"atl_change_percentage"`
+		ATLDate             map[string]string  `json:"atl_date"`
+		CirculatingSupply   *float64           `json:"circulating_supply"`
+		TotalSupply         *float64           `json:"total_supply"`
+		MaxSupply           *float64           `json:"max_supply"`
+	} `json:"market_data"`
+}
+
+func fetchCoinDetail(id string) (*coinDetail, error) {
+	query := url.Values{}
+	query.Set("localization", "false")
+	query.Set("tickers", "false")
+	query.Set("market_data", "true")
+	query.Set("community_data", "false")
+	query.Set("developer_data", "false")
+	query.Set("sparkline", "false")
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Get("https://api.coingecko.com/api/v3/coins/" + url.PathEscape(id) + "?" + query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 429 {
+		return nil, fmt.Errorf("rate limit exceeded")
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		// An unknown coin isn't a provider failure, don't count it against the breaker
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CoinGecko returned status %d", resp.StatusCode)
+	}
+
+	var coin coinDetail
+	if err := json.NewDecoder(resp.Body).Decode(&coin); err != nil {
+		return nil, err
+	}
+	return &coin, nil
+}
+
+// getCoinDetail returns a coin's fundamentals, or nil when CoinGecko doesn't
+// know the coin. Only CoinGecko has them, it still goes through
+// fetchWithFallback so an open breaker is respected.
+func getCoinDetail(id string) (*coinDetail, error) {
+	return withCache("coin:"+id, coinDetailCacheTTL, func() (*coinDetail, error) {
+		return fetchWithFallback(map[string]func() (*coinDetail, error){
+			"CoinGecko": func() (*coinDetail, error) {
+				return fetchCoinDetail(id)
+			},
+		})
+	})
+}
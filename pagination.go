@@ -0,0 +1,297 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	paginationPrefix = "page"
+	paginationTTL    = 15 * time.Minute
+	maxPageFields    = 24 // Discord's limit is 25, leaving room for a summary field
+	maxSelectOptions = 25
+)
+
+// paginator is the state behind a paginated message. Each page is one or more
+// embeds sent together.
+type paginator struct {
+	id      string
+	ownerID string // Only the user who ran the command can turn pages
+	pages   [][]*discordgo.MessageEmbed
+	labels  []string
+	page    int
+	expires time.Time
+
+	// actions returns extra component rows shown below the page controls
+	actions func(page int) []discordgo.MessageComponent
+}
+
+// pageAction is the payload of the page controls, the paginator keeps its own
+// expiry so the custom IDs never expire.
+type pageAction struct {
+	ID     string `json:"p"`
+	Action string `json:"a"`
+}
+
+var (
+	paginators      = make(map[string]*paginator)
+	paginatorsMutex sync.Mutex
+)
+
+// splitFields spreads items over as many copies of base as needed, perPage
+// fields each. Fields already on base, like a summary, are repeated after the
+// items on every page.
+func splitFields(base *discordgo.MessageEmbed, items []*discordgo.MessageEmbedField, perPage int) []*discordgo.MessageEmbed {
+	if len(items) == 0 {
+		return []*discordgo.MessageEmbed{base}
+	}
+
+	var embeds []*discordgo.MessageEmbed
+	for start := 0; start < len(items); start += perPage {
+		end := start + perPage
+		if end > len(items) {
+			end = len(items)
+		}
+		embed := *base
+		embed.Fields = append(append([]*discordgo.MessageEmbedField{}, items[start:end]...), base.Fields...)
+		embeds = append(embeds, &embed)
+	}
+	return embeds
+}
+
+// splitLines spreads lines over the descriptions of copies of base, perPage
+// lines each.
+func splitLines(base *discordgo.MessageEmbed, lines []string, perPage int) []*discordgo.MessageEmbed {
+	if len(lines) == 0 {
+		return []*discordgo.MessageEmbed{base}
+	}
+
+	var embeds []*discordgo.MessageEmbed
+	for start := 0; start < len(lines); start += perPage {
+		end := start + perPage
+		if end > len(lines) {
+			end = len(lines)
+		}
+		embed := *base
+		embed.Description = strings.Join(lines[start:end], "\n")
+		embeds = append(embeds, &embed)
+	}
+	return embeds
+}
+
+// embedPages groups embeds into pages of up to perPage embeds.
+func embedPages(embeds []*discordgo.MessageEmbed, perPage int) [][]*discordgo.MessageEmbed {
+	var pages [][]*discordgo.MessageEmbed
+	for start := 0; start < len(embeds); start += perPage {
+		end := start + perPage
+		if end > len(embeds) {
+			end = len(embeds)
+		}
+		pages = append(pages, embeds[start:end])
+	}
+	return pages
+}
+
+// newPaginator numbers the pages in their footers and keeps the paginator
+// until it hasn't been used for paginationTTL. Labels name the pages in the
+// jump menu, pages without one are called "Page N". A single page needs no
+// state and gets no page controls.
+func newPaginator(ownerID string, pages [][]*discordgo.MessageEmbed, labels ...string) *paginator {
+	p := &paginator{
+		ownerID: ownerID,
+		pages:   pages,
+		labels:  labels,
+		expires: time.Now().Add(paginationTTL),
+	}
+	if len(pages) < 2 {
+		return p
+	}
+
+	for idx, page := range pages {
+		last := *page[len(page)-1]
+		text := fmt.Sprintf("Page %d/%d", idx+1, len(pages))
+		if last.Footer != nil && last.Footer.Text != "" {
+			text += " • " + last.Footer.Text
+		}
+		last.Footer = &discordgo.MessageEmbedFooter{Text: text}
+		page[len(page)-1] = &last
+	}
+
+	p.id = newStateID()
+
+	paginatorsMutex.Lock()
+	now := time.Now()
+	for id, other := range paginators {
+		if now.After(other.expires) {
+			delete(paginators, id)
+		}
+	}
+	paginators[p.id] = p
+	paginatorsMutex.Unlock()
+	return p
+}
+
+// respondPaginated answers an interaction with the first page of a new paginator.
+func respondPaginated(s *discordgo.Session, i *discordgo.InteractionCreate, pages [][]*discordgo.MessageEmbed, flags discordgo.MessageFlags) error {
+	p := newPaginator(i.Member.User.ID, pages)
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     p.embeds(),
+			Components: p.components(),
+			Flags:      flags,
+		},
+	})
+}
+
+func (p *paginator) embeds() []*discordgo.MessageEmbed {
+	return p.pages[p.page]
+}
+
+func (p *paginator) label(page int) string {
+	if page < len(p.labels) && p.labels[page] != "" {
+		return p.labels[page]
+	}
+	return fmt.Sprintf("Page %d", page+1)
+}
+
+func (p *paginator) components() []discordgo.MessageComponent {
+	var actions []discordgo.MessageComponent
+	if p.actions != nil {
+		actions = p.actions(p.page)
+	}
+	if len(p.pages) < 2 {
+		return actions
+	}
+
+	customID := func(action string) string {
+		return componentID(paginationPrefix, 0, pageAction{ID: p.id, Action: action})
+	}
+
+	// The jump menu shows a window of pages around the current one
+	first := p.page - maxSelectOptions/2
+	if first > len(p.pages)-maxSelectOptions {
+		first = len(p.pages) - maxSelectOptions
+	}
+	if first < 0 {
+		first = 0
+	}
+	var options []discordgo.SelectMenuOption
+	for page := first; page < len(p.pages) && len(options) < maxSelectOptions; page++ {
+		options = append(options, discordgo.SelectMenuOption{
+			Label:   p.label(page),
+			Value:   strconv.Itoa(page),
+			Default: page == p.page,
+		})
+	}
+
+	controls := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "◀ Previous",
+					Style:    discordgo.SecondaryButton,
+					CustomID: customID("prev"),
+					Disabled: p.page == 0,
+				},
+				discordgo.Button{
+					Label:    fmt.Sprintf("%d/%d", p.page+1, len(p.pages)),
+					Style:    discordgo.SecondaryButton,
+					CustomID: customID("count"),
+					Disabled: true,
+				},
+				discordgo.Button{
+					Label:    "Next ▶",
+					Style:    discordgo.SecondaryButton,
+					CustomID: customID("next"),
+					Disabled: p.page == len(p.pages)-1,
+				},
+			},
+		},
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					CustomID:    customID("jump"),
+					Placeholder: "Jump to page…",
+					Options:     options,
+				},
+			},
+		},
+	}
+	return append(controls, actions...)
+}
+
+// handlePaginationComponent turns the page of a paginated message.
+func handlePaginationComponent(s *discordgo.Session, i *discordgo.InteractionCreate, action pageAction) {
+	data := i.MessageComponentData()
+	id := action.ID
+
+	paginatorsMutex.Lock()
+	p, ok := paginators[id]
+	if ok && time.Now().After(p.expires) {
+		delete(paginators, id)
+		ok = false
+	}
+	if !ok {
+		paginatorsMutex.Unlock()
+
+		// Drop the dead controls and tell the user why
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+		s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Content: "⌛ This list has expired, run the command again to browse it",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+
+	if i.Member.User.ID != p.ownerID {
+		paginatorsMutex.Unlock()
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("Only <@%s> can turn the pages of this list", p.ownerID),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	switch action.Action {
+	case "prev":
+		if p.page > 0 {
+			p.page--
+		}
+	case "next":
+		if p.page < len(p.pages)-1 {
+			p.page++
+		}
+	case "jump":
+		if len(data.Values) > 0 {
+			if page, err := strconv.Atoi(data.Values[0]); err == nil && page >= 0 && page < len(p.pages) {
+				p.page = page
+			}
+		}
+	}
+	p.expires = time.Now().Add(paginationTTL)
+	embeds, components := p.embeds(), p.components()
+	paginatorsMutex.Unlock()
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     embeds,
+			Components: components,
+		},
+	})
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// performanceStats summarizes a series of snapshots.
+type performanceStats struct {
+	TWR         float64 // Time-weighted return, ignoring deposits and withdrawals
+	MaxDrawdown float64 // Largest peak-to-trough fall of the time-weighted index
+	BTCReturn   float64
+	BTCValue    []float64 // Value had the same cash flows gone into BTC
+}
+
+// netFlow returns the cash put into the ledger between two times: buys
+// (including fees) minus sale proceeds.
+func netFlow(txs []*Transaction, after, until time.Time) float64 {
+	var flow float64
+	for _, tx := range txs {
+		if !tx.Timestamp.After(after) || tx.Timestamp.After(until) {
+			continue
+		}
+		if tx.Side == SideBuy {
+			flow += tx.Amount*tx.Price + tx.Fee
+		} else {
+			flow -= tx.Amount*tx.Price - tx.Fee
+		}
+	}
+	return flow
+}
+
+func computePerformance(history []PortfolioSnapshot, txs []*Transaction) performanceStats {
+	stats := performanceStats{BTCValue: make([]float64, len(history))}
+	if len(history) == 0 {
+		return stats
+	}
+
+	index, peak := 1.0, 1.0
+	var btcUnits float64
+	if history[0].BTCPrice > 0 {
+		btcUnits = history[0].Value / history[0].BTCPrice
+	}
+	stats.BTCValue[0] = history[0].Value
+
+	for k := 1; k < len(history); k++ {
+		prev, cur := history[k-1], history[k]
+		flow := netFlow(txs, prev.Date, cur.Date)
+
+		// Each period's return excludes money added or withdrawn during it
+		var r float64
+		switch {
+		case prev.Value > 0:
+			r = (cur.Value-flow)/prev.Value - 1
+		case flow > 0:
+			r = cur.Value/flow - 1
+		}
+		index *= 1 + r
+		peak = math.Max(peak, index)
+		stats.MaxDrawdown = math.Max(stats.MaxDrawdown, (peak-index)/peak)
+
+		if cur.BTCPrice > 0 {
+			btcUnits = math.Max(0, btcUnits+flow/cur.BTCPrice)
+			stats.BTCValue[k] = btcUnits * cur.BTCPrice
+		} else {
+			stats.BTCValue[k] = stats.BTCValue[k-1]
+		}
+	}
+
+	stats.TWR = index - 1
+	first, last := history[0], history[len(history)-1]
+	if first.BTCPrice > 0 && last.BTCPrice > 0 {
+		stats.BTCReturn = last.BTCPrice/first.BTCPrice - 1
+	}
+	return stats
+}
+
+func handlePerformanceCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	days := 30
+	var groupName string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "days":
+			days, _ = strconv.Atoi(opt.StringValue())
+		case "group":
+			groupName = opt.StringValue()
+		}
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	respond := func(content string) {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+	}
+
+	userID := i.Member.User.ID
+	key, title := userID, "Portfolio Performance"
+
+	portMutex.RLock()
+	var txs []*Transaction
+	var holdings []*Holding
+	if groupName != "" {
+		fund := findFundLocked(i.GuildID, userID, groupName)
+		if fund == nil {
+			portMutex.RUnlock()
+			respond(fmt.Sprintf("⚠️ You're not a member of a group named %q", groupName))
+			return
+		}
+		key, title = fundSnapshotKey(fund.ID), fmt.Sprintf("Group Performance: %s", fund.Name)
+		txs = append(txs, fund.Transactions...)
+		holdings = fund.Holdings()
+	} else {
+		txs = personalTransactionsLocked(userID)
+		holdings = holdingsList(txs, costBasisMethodLocked(userID))
+	}
+	portMutex.RUnlock()
+
+	now := time.Now().UTC()
+	var since time.Time
+	if days > 0 {
+		since = now.AddDate(0, 0, -days)
+	}
+
+	snapshotMutex.RLock()
+	var history []PortfolioSnapshot
+	for _, snapshot := range snapshots[key] {
+		if !snapshot.Date.Before(since) {
+			history = append(history, snapshot)
+		}
+	}
+	snapshotMutex.RUnlock()
+
+	if len(history) == 0 {
+		respond("No snapshots yet. Portfolio values are recorded daily, check back tomorrow.")
+		return
+	}
+
+	// End the series with the live value
+	prices := make(map[string]float64)
+	for _, symbol := range append([]string{"bitcoin"}, holdingSymbols(holdings)...) {
+		if price, err := getCryptoPrice(symbol); err == nil {
+			prices[symbol] = price.Price
+		} else {
+			log.Printf("Error getting price for %s: %v", symbol, err)
+		}
+	}
+	if live, ok := takeSnapshot(holdings, prices, now); ok && live.BTCPrice > 0 {
+		history = append(history, live)
+	}
+
+	stats := computePerformance(history, txs)
+	embed := createPerformanceEmbed(title, history, stats, days)
+	edit := &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	}
+
+	chart, err := renderPerformanceChart(history, stats)
+	if err != nil {
+		log.Printf("Error rendering performance chart: %v", err)
+	} else {
+		embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://performance.png"}
+		edit.Files = []*discordgo.File{{
+			Name:        "performance.png",
+			ContentType: "image/png",
+			Reader:      bytes.NewReader(chart),
+		}}
+	}
+
+	s.InteractionResponseEdit(i.Interaction, edit)
+}
+
+func holdingSymbols(holdings []*Holding) []string {
+	var symbols []string
+	for _, h := range holdings {
+		symbols = append(symbols, h.Symbol)
+	}
+	return symbols
+}
+
+func renderPerformanceChart(history []PortfolioSnapshot, stats performanceStats) ([]byte, error) {
+	series := []ChartSeries{{Name: "Value"}, {Name: "Cost Basis"}, {Name: "BTC"}}
+	for k, snapshot := range history {
+		t := float64(snapshot.Date.UnixMilli())
+		series[0].Points = append(series[0].Points, ChartPoint{Time: t, Value: snapshot.Value})
+		series[1].Points = append(series[1].Points, ChartPoint{Time: t, Value: snapshot.Cost})
+		series[2].Points = append(series[2].Points, ChartPoint{Time: t, Value: stats.BTCValue[k]})
+	}
+	return renderLineChart(series, math.NaN(), 800, 400)
+}
+
+func createPerformanceEmbed(title string, history []PortfolioSnapshot, stats performanceStats, days int) *discordgo.MessageEmbed {
+	first, last := history[0], history[len(history)-1]
+	btcValue := stats.BTCValue[len(stats.BTCValue)-1]
+
+	window := "All Time"
+	if days > 0 {
+		window = fmt.Sprintf("Last %d Days", days)
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       title,
+		Description: fmt.Sprintf("%s • %s to %s", window, first.Date.Format("2006-01-02"), last.Date.Format("2006-01-02")),
+		Color:       getColorForChange(stats.TWR),
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "Value",
+				Value:  formatUSD(last.Value),
+				Inline: true,
+			},
+			{
+				Name:   "Cost Basis",
+				Value:  formatUSD(last.Cost),
+				Inline: true,
+			},
+			{
+				Name:   "Unrealized P/L",
+				Value:  formatUSD(last.Value - last.Cost),
+				Inline: true,
+			},
+			{
+				Name:   "Time-Weighted Return",
+				Value:  fmt.Sprintf("%+.2f%%", stats.TWR*100),
+				Inline: true,
+			},
+			{
+				Name:   "Max Drawdown",
+				Value:  fmt.Sprintf("-%.2f%%", stats.MaxDrawdown*100),
+				Inline: true,
+			},
+			{
+				Name:   "BTC Return",
+				Value:  fmt.Sprintf("%+.2f%%", stats.BTCReturn*100),
+				Inline: true,
+			},
+			{
+				Name: "vs Holding BTC",
+				Value: fmt.Sprintf("Same deposits in BTC: %s\nDifference: %s",
+					formatUSD(btcValue), formatUSD(last.Value-btcValue)),
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("%s Value • %s Cost Basis • %s BTC • %d snapshots",
+				chartLegendEmojis[0], chartLegendEmojis[1], chartLegendEmojis[2], len(history)),
+		},
+	}
+}
+
+func performanceCommandOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "days",
+			Description: "Chart window (default 30 days)",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "7 days", Value: "7"},
+				{Name: "30 days", Value: "30"},
+				{Name: "90 days", Value: "90"},
+				{Name: "1 year", Value: "365"},
+				{Name: "All time", Value: "0"},
+			},
+		},
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "group",
+			Description:  "Show a group's performance instead of your own",
+			Required:     false,
+			Autocomplete: true,
+		},
+	}
+}
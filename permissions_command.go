@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// managerRoleID returns the role a guild lets manage price bots, alerts and
+// groups, or "" when only Manage Server can.
+func managerRoleID(guildID string) string {
+	settingsMutex.RLock()
+	defer settingsMutex.RUnlock()
+	if settings, ok := guildSettings[guildID]; ok {
+		return settings.ManagerRoleID
+	}
+	return ""
+}
+
+// isManager reports whether the member has the guild's manager role or the
+// Manage Server permission. It takes settingsMutex, so call it before taking
+// any lock that comes after it.
+func isManager(i *discordgo.InteractionCreate) bool {
+	if i.Member == nil {
+		return false
+	}
+	if i.Member.Permissions&(discordgo.PermissionManageServer|discordgo.PermissionAdministrator) != 0 {
+		return true
+	}
+	roleID := managerRoleID(i.GuildID)
+	if roleID == "" {
+		return false
+	}
+	for _, role := range i.Member.Roles {
+		if role == roleID {
+			return true
+		}
+	}
+	return false
+}
+
+func handlePermissionsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return
+	}
+
+	switch options[0].Name {
+	case "manager":
+		role := options[0].Options[0].RoleValue(s, i.GuildID)
+		if role == nil || role.ID == i.GuildID {
+			respondPermissions(s, i, "⚠️ Pick a role other than @everyone")
+			return
+		}
+		updateGuildSettings(i.GuildID, func(settings *GuildSettings) {
+			settings.ManagerRoleID = role.ID
+		})
+		respondPermissions(s, i, fmt.Sprintf("✅ Members with <@&%s> can now manage price bots, alerts, groups and the server watchlist", role.ID))
+	case "reset":
+		updateGuildSettings(i.GuildID, func(settings *GuildSettings) {
+			settings.ManagerRoleID = ""
+		})
+		respondPermissions(s, i, "✅ Only members with Manage Server can manage price bots, alerts, groups and the server watchlist now")
+	case "show":
+		content := "Only members with Manage Server can manage price bots, alerts, groups and the server watchlist. Set a role with `/permissions manager`."
+		if roleID := managerRoleID(i.GuildID); roleID != "" {
+			content = fmt.Sprintf("Members with <@&%s> or Manage Server can manage price bots, alerts, groups and the server watchlist.", roleID)
+		}
+		respondPermissions(s, i, content)
+	}
+}
+
+func respondPermissions(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         content,
+			Flags:           discordgo.MessageFlagsEphemeral,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
+
+func permissionsCommandOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "manager",
+			Description: "Let a role manage price bots, alerts, groups and the server watchlist",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "role",
+					Description: "Manager role",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "reset",
+			Description: "Remove the manager role, leaving Manage Server only",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "show",
+			Description: "Show who can manage price bots, alerts, groups and the server watchlist",
+		},
+	}
+}
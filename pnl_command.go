@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const pnlLotsPerPage = 15
+
+// periodStart returns the beginning of the reporting period, or the zero time for all-time.
+func periodStart(period string, now time.Time) time.Time {
+	switch period {
+	case "month":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	case "year":
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+func periodName(period string, now time.Time) string {
+	switch period {
+	case "month":
+		return now.Format("January 2006")
+	case "year":
+		return now.Format("2006")
+	default:
+		return "All Time"
+	}
+}
+
+func handlePnLCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := i.Member.User.ID
+	period := "all"
+	var method, symbol string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "period":
+			period = opt.StringValue()
+		case "method":
+			method = opt.StringValue()
+		case "symbol":
+			symbol = parseGeckoID(opt.StringValue())
+		}
+	}
+
+	portMutex.Lock()
+	// Choosing a method also makes it the user's default
+	methodChanged := method != ""
+	if methodChanged {
+		portfolio := getOrCreatePortfolioLocked(userID)
+		portfolio.CostBasisMethod = normalizeCostBasisMethod(method)
+	}
+	method = costBasisMethodLocked(userID)
+	report := replayLedger(personalTransactionsLocked(userID), method)
+	portMutex.Unlock()
+
+	if methodChanged {
+		if err := savePortfolio(userID); err != nil {
+			log.Printf("Error saving portfolios: %v", err)
+		}
+	}
+
+	now := time.Now()
+	since := periodStart(period, now)
+
+	var lots []RealizedLot
+	var totalProceeds, totalCost float64
+	for _, lot := range report.Realized {
+		if lot.Sold.Before(since) || (symbol != "" && lot.Symbol != symbol) {
+			continue
+		}
+		lots = append(lots, lot)
+		totalProceeds += lot.Proceeds
+		totalCost += lot.CostBasis
+	}
+	totalPL := totalProceeds - totalCost
+
+	// Most recent sells first
+	sort.SliceStable(lots, func(a, b int) bool {
+		return lots[a].Sold.After(lots[b].Sold)
+	})
+
+	embed := &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("Realized P/L — %s", periodName(period, now)),
+		Color: getColorForChange(totalPL),
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "Proceeds",
+				Value:  fmt.Sprintf("$%.2f", totalProceeds),
+				Inline: true,
+			},
+			{
+				Name:   "Cost Basis",
+				Value:  fmt.Sprintf("$%.2f", totalCost),
+				Inline: true,
+			},
+			{
+				Name:   "Realized P/L",
+				Value:  fmt.Sprintf("$%.2f", totalPL),
+				Inline: true,
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Cost basis: %s • %s", costBasisNames[method], now.Format("2006-01-02 15:04:05 MST")),
+		},
+	}
+	if symbol != "" {
+		embed.Title += " for " + strings.ToUpper(symbol)
+	}
+
+	var lines []string
+	if len(lots) == 0 {
+		embed.Description = "No sells in this period"
+	}
+	for _, lot := range lots {
+		lines = append(lines, fmt.Sprintf("`%s` %.4f %s bought %s @ $%.2f → sold @ $%.2f: **%+.2f**",
+			lot.Sold.Format("2006-01-02"),
+			lot.Amount,
+			strings.ToUpper(lot.Symbol),
+			lot.Acquired.Format("2006-01-02"),
+			lot.CostBasis/lot.Amount,
+			lot.Proceeds/lot.Amount,
+			lot.PL(),
+		))
+	}
+
+	pages := embedPages(splitLines(embed, lines, pnlLotsPerPage), 1)
+	respondPaginated(s, i, pages, discordgo.MessageFlagsEphemeral)
+}
+
+func pnlCommandOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "period",
+			Description: "Reporting period (default all-time)",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "This month", Value: "month"},
+				{Name: "This year", Value: "year"},
+				{Name: "All time", Value: "all"},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "method",
+			Description: "Cost basis method, saved as your default",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "FIFO", Value: CostBasisFIFO},
+				{Name: "LIFO", Value: CostBasisLIFO},
+				{Name: "Weighted average", Value: CostBasisAverage},
+			},
+		},
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "symbol",
+			Description:  "Only report on this cryptocurrency",
+			Required:     false,
+			Autocomplete: true,
+		},
+	}
+}
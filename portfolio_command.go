@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const maxImportFileSize = 2 << 20 // 2 MiB
+
+func handlePortfolioCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return
+	}
+
+	switch options[0].Name {
+	case "export":
+		handlePortfolioExport(s, i, options[0].Options)
+	case "import":
+		handlePortfolioImport(s, i, options[0].Options)
+	}
+}
+
+func handlePortfolioExport(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	format := "csv"
+	for _, opt := range options {
+		if opt.Name == "format" {
+			format = opt.StringValue()
+		}
+	}
+
+	userID := i.Member.User.ID
+	portMutex.RLock()
+	method := costBasisMethodLocked(userID)
+	txs := personalTransactionsLocked(userID)
+	holdings := holdingsList(txs, method)
+	portMutex.RUnlock()
+
+	if len(txs) == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "You have no personal transactions to export.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	stamp := time.Now().Format("2006-01-02")
+	var files []*discordgo.File
+	if format == "json" {
+		data, err := exportPortfolioJSON(txs, holdings, method)
+		if err != nil {
+			log.Printf("Error exporting portfolio: %v", err)
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "⚠️ Couldn't build the export, try the CSV format instead.",
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+		files = append(files, &discordgo.File{
+			Name:        fmt.Sprintf("portfolio-%s.json", stamp),
+			ContentType: "application/json",
+			Reader:      bytes.NewReader(data),
+		})
+	} else {
+		files = append(files,
+			&discordgo.File{
+				Name:        fmt.Sprintf("holdings-%s.csv", stamp),
+				ContentType: "text/csv",
+				Reader:      bytes.NewReader(exportHoldingsCSV(holdings)),
+			},
+			&discordgo.File{
+				Name:        fmt.Sprintf("transactions-%s.csv", stamp),
+				ContentType: "text/csv",
+				Reader:      bytes.NewReader(exportTransactionsCSV(txs)),
+			},
+		)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("📦 Exported %d holdings and %d transactions. The files can be re-imported with `/portfolio import`.",
+				len(holdings), len(txs)),
+			Files: files,
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func exportPortfolioJSON(txs []*Transaction, holdings []*Holding, method string) ([]byte, error) {
+	export := portfolioExport{
+		ExportedAt:      time.Now().UTC(),
+		CostBasisMethod: method,
+		Holdings:        []exportHolding{},
+		Transactions:    txs,
+	}
+	for _, h := range holdings {
+		export.Holdings = append(export.Holdings, exportHolding{
+			Symbol:     h.Symbol,
+			Amount:     h.Amount,
+			CostBasis:  h.CostBasis,
+			AvgCost:    h.AvgCost(),
+			RealizedPL: h.RealizedPL,
+		})
+	}
+	return json.MarshalIndent(export, "", "  ")
+}
+
+func formatCSVNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func exportHoldingsCSV(holdings []*Holding) []byte {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"symbol", "amount", "cost_basis", "avg_cost", "realized_pl"})
+	for _, h := range holdings {
+		writer.Write([]string{
+			h.Symbol,
+			formatCSVNumber(h.Amount),
+			formatCSVNumber(h.CostBasis),
+			formatCSVNumber(h.AvgCost()),
+			formatCSVNumber(h.RealizedPL),
+		})
+	}
+	writer.Flush()
+	return buf.Bytes()
+}
+
+func exportTransactionsCSV(txs []*Transaction) []byte {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write(nativeCSVHeader)
+	for _, tx := range txs {
+		writer.Write([]string{
+			tx.ID,
+			tx.Timestamp.UTC().Format(time.RFC3339),
+			tx.Side,
+			tx.Symbol,
+			formatCSVNumber(tx.Amount),
+			formatCSVNumber(tx.Price),
+			formatCSVNumber(tx.Fee),
+			tx.Note,
+		})
+	}
+	writer.Flush()
+	return buf.Bytes()
+}
+
+func downloadAttachment(url string) ([]byte, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error downloading file: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImportFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error downloading file: %w", err)
+	}
+	if len(data) > maxImportFileSize {
+		return nil, fmt.Errorf("the file is larger than %d KB", maxImportFileSize>>10)
+	}
+	return data, nil
+}
+
+func handlePortfolioImport(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	var attachmentID string
+	format := "auto"
+	dryRun := false
+	for _, opt := range options {
+		switch opt.Name {
+		case "file":
+			attachmentID = fmt.Sprint(opt.Value)
+		case "format":
+			format = opt.StringValue()
+		case "dry_run":
+			dryRun = opt.BoolValue()
+		}
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	fail := func(err error) {
+		content := fmt.Sprintf("⚠️ %s", err)
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+	}
+
+	resolved := i.ApplicationCommandData().Resolved
+	if resolved == nil || resolved.Attachments[attachmentID] == nil {
+		fail(fmt.Errorf("no file attached"))
+		return
+	}
+	attachment := resolved.Attachments[attachmentID]
+	if attachment.Size > maxImportFileSize {
+		fail(fmt.Errorf("the file is larger than %d KB", maxImportFileSize>>10))
+		return
+	}
+
+	data, err := downloadAttachment(attachment.URL)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	userID := i.Member.User.ID
+	result, err := parseImport(data, format, userID)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	portMutex.Lock()
+	accepted, duplicates, errs := mergeImport(personalTransactionsLocked(userID), result.Transactions)
+	if !dryRun && len(accepted) > 0 {
+		portfolio := getOrCreatePortfolioLocked(userID)
+		portfolio.Transactions = append(portfolio.Transactions, accepted...)
+		sortTransactions(portfolio.Transactions)
+	}
+	portMutex.Unlock()
+
+	if !dryRun && len(accepted) > 0 {
+		if err := savePortfolio(userID); err != nil {
+			log.Printf("Error saving portfolios: %v", err)
+		}
+	}
+
+	embed := createImportEmbed(attachment.Filename, result, accepted, duplicates, append(result.Errors, errs...), dryRun)
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+}
+
+func createImportEmbed(filename string, result *importResult, accepted []*Transaction, duplicates int, errs []string, dryRun bool) *discordgo.MessageEmbed {
+	const maxListed = 10
+
+	embed := &discordgo.MessageEmbed{
+		Title: "📥 Import Complete",
+		Color: 0x00ff00,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "Format",
+				Value:  importFormatNames[result.Format],
+				Inline: true,
+			},
+			{
+				Name:   "Rows",
+				Value:  fmt.Sprintf("%d", result.Rows),
+				Inline: true,
+			},
+			{
+				Name:   "New Transactions",
+				Value:  fmt.Sprintf("%d", len(accepted)),
+				Inline: true,
+			},
+			{
+				Name:   "Duplicates Skipped",
+				Value:  fmt.Sprintf("%d", duplicates),
+				Inline: true,
+			},
+			{
+				Name:   "Rejected",
+				Value:  fmt.Sprintf("%d", len(errs)),
+				Inline: true,
+			},
+			{
+				Name:   "Not Trades",
+				Value:  fmt.Sprintf("%d", result.Ignored),
+				Inline: true,
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: filename,
+		},
+	}
+	if dryRun {
+		embed.Title = "🔍 Import Preview (dry run)"
+		embed.Description = "Nothing was saved. Run the import again without `dry_run` to apply it."
+		embed.Color = 0x3498db
+	}
+	if len(errs) > 0 && len(accepted) == 0 {
+		embed.Color = 0xff0000
+	}
+
+	if len(accepted) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "By Coin",
+			Value: truncateFieldValue(strings.Join(importSummary(accepted), "\n")),
+		})
+
+		var lines []string
+		for idx, tx := range accepted {
+			if idx == maxListed {
+				lines = append(lines, fmt.Sprintf("…and %d more", len(accepted)-maxListed))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("`%s` **%s** %.8g %s @ %s",
+				tx.Timestamp.Format("2006-01-02"), strings.ToUpper(tx.Side), tx.Amount, strings.ToUpper(tx.Symbol), formatUSD(tx.Price)))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "Transactions",
+			Value: truncateFieldValue(strings.Join(lines, "\n")),
+		})
+	}
+
+	if len(errs) > 0 {
+		shown := errs
+		if len(shown) > maxListed {
+			shown = append(shown[:maxListed:maxListed], fmt.Sprintf("…and %d more", len(errs)-maxListed))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "Problems",
+			Value: truncateFieldValue(strings.Join(shown, "\n")),
+		})
+	}
+
+	return embed
+}
+
+func portfolioCommandOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "export",
+			Description: "Download your holdings and transactions",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "format",
+					Description: "File format (default CSV)",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "CSV", Value: "csv"},
+						{Name: "JSON", Value: "json"},
+					},
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "import",
+			Description: "Import transactions from a file",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "file",
+					Description: "CSV or JSON file",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "format",
+					Description: "File format (detected automatically by default)",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Auto-detect", Value: "auto"},
+						{Name: "Bot CSV export", Value: ImportFormatNative},
+						{Name: "Bot JSON export", Value: ImportFormatJSON},
+						{Name: "Binance trade history", Value: ImportFormatBinance},
+						{Name: "Coinbase transaction history", Value: ImportFormatCoinbase},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "dry_run",
+					Description: "Preview the import without saving anything",
+					Required:    false,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,604 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported import formats
+const (
+	ImportFormatNative   = "native"
+	ImportFormatJSON     = "json"
+	ImportFormatBinance  = "binance"
+	ImportFormatCoinbase = "coinbase"
+)
+
+var importFormatNames = map[string]string{
+	ImportFormatNative:   "Bot CSV",
+	ImportFormatJSON:     "Bot JSON",
+	ImportFormatBinance:  "Binance trade history",
+	ImportFormatCoinbase: "Coinbase transaction history",
+}
+
+const maxImportRows = 5000
+
+// Quote currencies treated as USD when importing exchange trades
+var usdQuoteAssets = []string{"USDT", "USDC", "BUSD", "FDUSD", "TUSD", "USD"}
+
+// importResult holds the transactions parsed from a file along with the rows
+// that were rejected.
+type importResult struct {
+	Format       string
+	Rows         int
+	Transactions []*Transaction
+	Errors       []string
+	Ignored      int // Rows that aren't trades, such as deposits or rewards
+}
+
+func (r *importResult) rowError(line int, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf("line %d: %s", line, fmt.Sprintf(format, args...)))
+}
+
+// portfolioExport is the JSON layout written by /portfolio export.
+type portfolioExport struct {
+	ExportedAt      time.Time       `json:"exported_at"`
+	CostBasisMethod string          `json:"cost_basis_method"`
+	Holdings        []exportHolding `json:"holdings"`
+	Transactions    []*Transaction  `json:"transactions"`
+}
+
+type exportHolding struct {
+	Symbol     string  `json:"symbol"`
+	Amount     float64 `json:"amount"`
+	CostBasis  float64 `json:"cost_basis"`
+	AvgCost    float64 `json:"avg_cost"`
+	RealizedPL float64 `json:"realized_pl"`
+}
+
+// Columns of the bot's own CSV export
+var nativeCSVHeader = []string{"id", "date", "side", "symbol", "amount", "price", "fee", "note"}
+
+// detectImportFormat guesses the format from the file's contents.
+func detectImportFormat(data []byte) (string, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return "", fmt.Errorf("the file is empty")
+	}
+	if trimmed[0] == '{' || trimmed[0] == '[' {
+		return ImportFormatJSON, nil
+	}
+
+	header := strings.ToLower(string(trimmed))
+	switch {
+	case strings.Contains(header, "date(utc)"):
+		return ImportFormatBinance, nil
+	case strings.Contains(header, "transaction type") && strings.Contains(header, "quantity transacted"):
+		return ImportFormatCoinbase, nil
+	case strings.HasPrefix(header, "id,date,side") || strings.HasPrefix(header, "date,side"):
+		return ImportFormatNative, nil
+	}
+	return "", fmt.Errorf("unrecognized file format, pick one explicitly")
+}
+
+// parseImport parses an uploaded file into transactions for the user.
+func parseImport(data []byte, format, userID string) (*importResult, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	if format == "" || format == "auto" {
+		detected, err := detectImportFormat(data)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+
+	var (
+		result *importResult
+		err    error
+	)
+	switch format {
+	case ImportFormatJSON:
+		result, err = parseJSONImport(data)
+	case ImportFormatNative, ImportFormatBinance, ImportFormatCoinbase:
+		result, err = parseCSVImport(data, format)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Format = format
+	for _, tx := range result.Transactions {
+		tx.Type = "personal"
+		tx.CreatedBy = userID
+		if tx.ID == "" {
+			tx.ID = newTransactionID()
+		}
+	}
+	return result, nil
+}
+
+func parseJSONImport(data []byte) (*importResult, error) {
+	// Accept either a full export or a bare list of transactions
+	var export portfolioExport
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &export.Transactions); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	} else if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if len(export.Transactions) > maxImportRows {
+		return nil, fmt.Errorf("too many transactions, the limit is %d", maxImportRows)
+	}
+
+	result := &importResult{Rows: len(export.Transactions)}
+	for idx, tx := range export.Transactions {
+		if tx == nil {
+			continue
+		}
+		tx.Side = strings.ToLower(tx.Side)
+		tx.Symbol = parseGeckoID(tx.Symbol)
+		tx.Contributions = nil
+		tx.Participants = nil
+		if err := validateImportedTransaction(tx); err != nil {
+			result.rowError(idx+1, "%v", err)
+			continue
+		}
+		result.Transactions = append(result.Transactions, tx)
+	}
+	return result, nil
+}
+
+func parseCSVImport(data []byte, format string) (*importResult, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	result := &importResult{}
+	var columns map[string]int
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(record) == 0 || (len(record) == 1 && strings.TrimSpace(record[0]) == "") {
+			continue
+		}
+
+		// Coinbase reports start with a few lines of preamble before the header
+		if columns == nil {
+			if isImportHeader(record, format) {
+				columns = csvColumns(record)
+			} else if format != ImportFormatCoinbase {
+				return nil, fmt.Errorf("line %d: expected a %s header", line, importFormatNames[format])
+			}
+			continue
+		}
+
+		result.Rows++
+		if result.Rows > maxImportRows {
+			return nil, fmt.Errorf("too many rows, the limit is %d", maxImportRows)
+		}
+
+		var tx *Transaction
+		switch format {
+		case ImportFormatNative:
+			tx, err = parseNativeRow(record, columns)
+		case ImportFormatBinance:
+			tx, err = parseBinanceRow(record, columns)
+		case ImportFormatCoinbase:
+			tx, err = parseCoinbaseRow(record, columns)
+		}
+		if err != nil {
+			result.rowError(line, "%v", err)
+			continue
+		}
+		if tx == nil {
+			result.Ignored++
+			continue
+		}
+		if err := validateImportedTransaction(tx); err != nil {
+			result.rowError(line, "%v", err)
+			continue
+		}
+		result.Transactions = append(result.Transactions, tx)
+	}
+
+	if columns == nil {
+		return nil, fmt.Errorf("no %s header found", importFormatNames[format])
+	}
+	return result, nil
+}
+
+func isImportHeader(record []string, format string) bool {
+	columns := csvColumns(record)
+	has := func(name string) bool {
+		_, ok := columns[name]
+		return ok
+	}
+	switch format {
+	case ImportFormatNative:
+		return has("date") && has("side") && has("symbol")
+	case ImportFormatBinance:
+		return has("date(utc)")
+	case ImportFormatCoinbase:
+		return has("timestamp") && has("transaction type")
+	}
+	return false
+}
+
+func csvColumns(header []string) map[string]int {
+	columns := make(map[string]int, len(header))
+	for idx, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = idx
+	}
+	return columns
+}
+
+func csvField(record []string, columns map[string]int, name string) string {
+	idx, ok := columns[name]
+	if !ok || idx >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[idx])
+}
+
+// csvFieldAny returns the first of the named columns the file has, for
+// columns that were renamed between versions of a report.
+func csvFieldAny(record []string, columns map[string]int, names ...string) (string, bool) {
+	for _, name := range names {
+		if _, ok := columns[name]; ok {
+			return csvField(record, columns, name), true
+		}
+	}
+	return "", false
+}
+
+// parseImportNumber parses numbers such as "1,234.50" or "$12.00".
+func parseImportNumber(value string) (float64, error) {
+	cleaned := strings.NewReplacer(",", "", "$", "", " ", "").Replace(value)
+	if cleaned == "" {
+		return 0, nil
+	}
+	number, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	return number, nil
+}
+
+// splitAssetAmount splits Binance values such as "0.5BTC" into the number and asset.
+func splitAssetAmount(value string) (float64, string, error) {
+	value = strings.TrimSpace(value)
+	end := strings.IndexFunc(value, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.' || r == ',' || r == '-')
+	})
+	if end == -1 {
+		end = len(value)
+	}
+	amount, err := parseImportNumber(value[:end])
+	return amount, strings.ToUpper(value[end:]), err
+}
+
+func parseImportTime(value string) (time.Time, error) {
+	layouts := []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05 MST",
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+func parseNativeRow(record []string, columns map[string]int) (*Transaction, error) {
+	timestamp, err := parseImportTime(csvField(record, columns, "date"))
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &Transaction{
+		ID:        csvField(record, columns, "id"),
+		Side:      strings.ToLower(csvField(record, columns, "side")),
+		Symbol:    parseGeckoID(csvField(record, columns, "symbol")),
+		Note:      csvField(record, columns, "note"),
+		Timestamp: timestamp,
+	}
+	for name, target := range map[string]*float64{"amount": &tx.Amount, "price": &tx.Price, "fee": &tx.Fee} {
+		if *target, err = parseImportNumber(csvField(record, columns, name)); err != nil {
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// splitTradingPair splits a pair such as "BTCUSDT" into its base asset, requiring a USD quote.
+func splitTradingPair(pair string) (string, error) {
+	pair = strings.ToUpper(pair)
+	for _, quote := range usdQuoteAssets {
+		if strings.HasSuffix(pair, quote) && len(pair) > len(quote) {
+			return strings.TrimSuffix(pair, quote), nil
+		}
+	}
+	return "", fmt.Errorf("pair %s isn't quoted in USD or a stablecoin", pair)
+}
+
+func isUSDAsset(asset string) bool {
+	for _, quote := range usdQuoteAssets {
+		if strings.EqualFold(asset, quote) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBinanceRow handles both the current export
+// (Date(UTC),Pair,Side,Price,Executed,Amount,Fee) and the older one
+// (Date(UTC),Market,Type,Price,Amount,Total,Fee,Fee Coin).
+func parseBinanceRow(record []string, columns map[string]int) (*Transaction, error) {
+	timestamp, err := parseImportTime(csvField(record, columns, "date(utc)"))
+	if err != nil {
+		return nil, err
+	}
+
+	_, newFormat := columns["executed"]
+	pair := csvField(record, columns, "market")
+	side := csvField(record, columns, "type")
+	if newFormat {
+		pair = csvField(record, columns, "pair")
+		side = csvField(record, columns, "side")
+	}
+
+	base, err := splitTradingPair(pair)
+	if err != nil {
+		return nil, err
+	}
+	geckoID, ok := geckoIDForSymbol(base)
+	if !ok {
+		return nil, fmt.Errorf("unknown coin %s", base)
+	}
+
+	price, err := parseImportNumber(csvField(record, columns, "price"))
+	if err != nil {
+		return nil, err
+	}
+
+	var amount, fee float64
+	var feeAsset string
+	if newFormat {
+		if amount, _, err = splitAssetAmount(csvField(record, columns, "executed")); err != nil {
+			return nil, err
+		}
+		if fee, feeAsset, err = splitAssetAmount(csvField(record, columns, "fee")); err != nil {
+			return nil, err
+		}
+	} else {
+		if amount, err = parseImportNumber(csvField(record, columns, "amount")); err != nil {
+			return nil, err
+		}
+		if fee, err = parseImportNumber(csvField(record, columns, "fee")); err != nil {
+			return nil, err
+		}
+		feeAsset = strings.ToUpper(csvField(record, columns, "fee coin"))
+	}
+
+	tx := &Transaction{
+		Side:      strings.ToLower(side),
+		Symbol:    geckoID,
+		Amount:    amount,
+		Price:     price,
+		Note:      "Imported from Binance",
+		Timestamp: timestamp,
+	}
+
+	// Fees are stored in USD, so only fees paid in the quote or base asset carry over
+	switch {
+	case fee == 0:
+	case isUSDAsset(feeAsset):
+		tx.Fee = fee
+	case strings.EqualFold(feeAsset, base):
+		tx.Fee = fee * price
+	default:
+		tx.Note += fmt.Sprintf(" (fee %.8g %s not included)", fee, feeAsset)
+	}
+	return tx, nil
+}
+
+// parseCoinbaseRow handles Coinbase's transaction history report. Rows other
+// than buys and sells (sends, receives, conversions, rewards) are ignored.
+func parseCoinbaseRow(record []string, columns map[string]int) (*Transaction, error) {
+	var side string
+	switch strings.ToLower(csvField(record, columns, "transaction type")) {
+	case "buy", "advanced trade buy":
+		side = SideBuy
+	case "sell", "advanced trade sell":
+		side = SideSell
+	default:
+		return nil, nil
+	}
+
+	timestamp, err := parseImportTime(csvField(record, columns, "timestamp"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Older reports prefix the price columns with "Spot"
+	if currency, _ := csvFieldAny(record, columns, "price currency", "spot price currency"); currency != "" && !isUSDAsset(currency) {
+		return nil, fmt.Errorf("prices in %s aren't supported, export the report in USD", currency)
+	}
+
+	asset := csvField(record, columns, "asset")
+	geckoID, ok := geckoIDForSymbol(asset)
+	if !ok {
+		return nil, fmt.Errorf("unknown coin %s", asset)
+	}
+
+	amount, err := parseImportNumber(csvField(record, columns, "quantity transacted"))
+	if err != nil {
+		return nil, err
+	}
+	priceField, ok := csvFieldAny(record, columns, "price at transaction", "spot price at transaction")
+	if !ok {
+		return nil, fmt.Errorf("missing the Price at Transaction column")
+	}
+	price, err := parseImportNumber(priceField)
+	if err != nil {
+		return nil, err
+	}
+	if price <= 0 {
+		return nil, fmt.Errorf("missing price for the %s %s", side, asset)
+	}
+	fee, err := parseImportNumber(csvField(record, columns, "fees and/or spread"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Transaction{
+		Side:      side,
+		Symbol:    geckoID,
+		Amount:    math.Abs(amount),
+		Price:     price,
+		Fee:       fee,
+		Note:      "Imported from Coinbase",
+		Timestamp: timestamp,
+	}, nil
+}
+
+func validateImportedTransaction(tx *Transaction) error {
+	switch {
+	case tx.Side != SideBuy && tx.Side != SideSell:
+		return fmt.Errorf("side must be buy or sell, got %q", tx.Side)
+	case tx.Symbol == "":
+		return fmt.Errorf("missing symbol")
+	case tx.Amount <= 0:
+		return fmt.Errorf("amount must be positive")
+	case tx.Price < 0 || tx.Fee < 0:
+		return fmt.Errorf("price and fee can't be negative")
+	case tx.Timestamp.IsZero():
+		return fmt.Errorf("missing date")
+	case tx.Timestamp.After(time.Now()):
+		return fmt.Errorf("date %s is in the future", tx.Timestamp.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// transactionKey identifies a trade independently of its ID, so re-importing
+// the same exchange report doesn't record it twice.
+func transactionKey(tx *Transaction) string {
+	return fmt.Sprintf("%s|%s|%d|%.8f|%.8f", tx.Side, tx.Symbol, tx.Timestamp.Unix(), tx.Amount, tx.Price)
+}
+
+// mergeImport returns the imported transactions that aren't already in the
+// ledger, rejecting sells of more coins than are held at the time.
+func mergeImport(existing, imported []*Transaction) (accepted []*Transaction, duplicates int, errs []string) {
+	seenIDs := make(map[string]bool)
+	seenKeys := make(map[string]bool)
+	for _, tx := range existing {
+		seenIDs[tx.ID] = true
+		seenKeys[transactionKey(tx)] = true
+	}
+
+	var fresh []*Transaction
+	for _, tx := range imported {
+		key := transactionKey(tx)
+		if seenIDs[tx.ID] || seenKeys[key] {
+			duplicates++
+			continue
+		}
+		seenIDs[tx.ID] = true
+		seenKeys[key] = true
+		fresh = append(fresh, tx)
+	}
+
+	// Replay everything in order so sells are checked against the holdings at that time
+	isImported := make(map[*Transaction]bool, len(fresh))
+	merged := append([]*Transaction{}, existing...)
+	for _, tx := range fresh {
+		isImported[tx] = true
+		merged = append(merged, tx)
+	}
+	sortTransactions(merged)
+
+	held := make(map[string]float64)
+	for _, tx := range merged {
+		if tx.Side == SideBuy {
+			held[tx.Symbol] += tx.Amount
+			if isImported[tx] {
+				accepted = append(accepted, tx)
+			}
+			continue
+		}
+
+		if tx.Amount > held[tx.Symbol]+1e-9 {
+			if isImported[tx] {
+				errs = append(errs, fmt.Sprintf("%s sell of %.8g %s exceeds the %.8g held",
+					tx.Timestamp.Format("2006-01-02"), tx.Amount, strings.ToUpper(tx.Symbol), held[tx.Symbol]))
+				continue
+			}
+		}
+		held[tx.Symbol] = math.Max(0, held[tx.Symbol]-tx.Amount)
+		if isImported[tx] {
+			accepted = append(accepted, tx)
+		}
+	}
+	return accepted, duplicates, errs
+}
+
+// importSummary counts buys and sells per coin, largest first.
+func importSummary(txs []*Transaction) []string {
+	type counts struct {
+		symbol      string
+		buys, sells int
+	}
+	bySymbol := make(map[string]*counts)
+	for _, tx := range txs {
+		c, exists := bySymbol[tx.Symbol]
+		if !exists {
+			c = &counts{symbol: tx.Symbol}
+			bySymbol[tx.Symbol] = c
+		}
+		if tx.Side == SideBuy {
+			c.buys++
+		} else {
+			c.sells++
+		}
+	}
+
+	var list []*counts
+	for _, c := range bySymbol {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(a, b int) bool {
+		if list[a].buys+list[a].sells != list[b].buys+list[b].sells {
+			return list[a].buys+list[a].sells > list[b].buys+list[b].sells
+		}
+		return list[a].symbol < list[b].symbol
+	})
+
+	var lines []string
+	for _, c := range list {
+		lines = append(lines, fmt.Sprintf("%s: %d buys, %d sells", strings.ToUpper(c.symbol), c.buys, c.sells))
+	}
+	return lines
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectImportFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{"json export", `{"transactions":[]}`, ImportFormatJSON, false},
+		{"json list", "  [{}]", ImportFormatJSON, false},
+		{"binance", "Date(UTC),Pair,Side,Price,Executed,Amount,Fee\n", ImportFormatBinance, false},
+		{"coinbase with preamble", "You can use this transaction report...\nUser,someone\nTimestamp,Transaction Type,Asset,Quantity Transacted\n", ImportFormatCoinbase, false},
+		{"native", "id,date,side,symbol,amount,price,fee,note\n", ImportFormatNative, false},
+		{"native with BOM", "\xef\xbb\xbfdate,side,symbol,amount,price\n", ImportFormatNative, false},
+		{"empty", "  \n", "", true},
+		{"unknown", "foo,bar\n1,2\n", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := detectImportFormat([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("format = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitAssetAmount(t *testing.T) {
+	tests := []struct {
+		value  string
+		amount float64
+		asset  string
+	}{
+		{"0.5BTC", 0.5, "BTC"},
+		{"1,234.5USDT", 1234.5, "USDT"},
+		{" 0.001bnb ", 0.001, "BNB"},
+		{"42", 42, ""},
+		{"", 0, ""},
+	}
+	for _, tt := range tests {
+		amount, asset, err := splitAssetAmount(tt.value)
+		if err != nil {
+			t.Errorf("splitAssetAmount(%q): %v", tt.value, err)
+			continue
+		}
+		if amount != tt.amount || asset != tt.asset {
+			t.Errorf("splitAssetAmount(%q) = %v, %q; want %v, %q", tt.value, amount, asset, tt.amount, tt.asset)
+		}
+	}
+
+	if _, _, err := splitAssetAmount("1.2.3BTC"); err == nil {
+		t.Error("expected an error for a malformed number")
+	}
+}
+
+// importedTrade is the part of a parsed transaction the tests compare.
+type importedTrade struct {
+	side   string
+	symbol string
+	amount float64
+	price  float64
+	fee    float64
+}
+
+func TestParseImport(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		data    string
+		want    []importedTrade
+		errors  int
+		ignored int
+	}{
+		{
+			name:   "binance current format",
+			format: "auto",
+			data: `Date(UTC),Pair,Side,Price,Executed,Amount,Fee
+2024-01-05 10:00:00,BTCUSDT,BUY,40000,0.5BTC,20000USDT,10USDT
+2024-02-05 10:00:00,ETHUSDT,SELL,2500,2ETH,5000USDT,0.001ETH
+2024-02-06 10:00:00,BTCEUR,BUY,38000,0.1BTC,3800EUR,1EUR
+`,
+			want: []importedTrade{
+				{SideBuy, "bitcoin", 0.5, 40000, 10},
+				{SideSell, "ethereum", 2, 2500, 2.5},
+			},
+			errors: 1,
+		},
+		{
+			name:   "binance legacy format",
+			format: ImportFormatBinance,
+			data: `Date(UTC),Market,Type,Price,Amount,Total,Fee,Fee Coin
+2021-03-01 08:00:00,BTCUSDT,BUY,50000,0.2,10000,0.0002,BTC
+2021-03-02 08:00:00,SOLBUSD,SELL,15,10,150,0.1,BNB
+`,
+			want: []importedTrade{
+				{SideBuy, "bitcoin", 0.2, 50000, 10},
+				{SideSell, "solana", 10, 15, 0},
+			},
+		},
+		{
+			name:   "coinbase current headers",
+			format: "auto",
+			data: `Transactions
+User,someone@example.com,abc123
+
+Timestamp,Transaction Type,Asset,Quantity Transacted,Price Currency,Price at Transaction,Subtotal,Total (inclusive of fees and/or spread),Fees and/or Spread,Notes
+2024-01-05T10:00:00Z,Buy,BTC,0.1,USD,$40000.00,$4000.00,$4010.00,$10.00,Bought BTC
+2024-01-06T10:00:00Z,Send,BTC,-0.05,USD,$41000.00,,,,Sent BTC
+2024-01-07T10:00:00Z,Advanced Trade Sell,BTC,-0.05,USD,"$42,000.00",$2100.00,$2099.00,$1.00,Sold BTC
+2024-01-08T10:00:00Z,Buy,ETH,1,USD,,,,,No price
+`,
+			want: []importedTrade{
+				{SideBuy, "bitcoin", 0.1, 40000, 10},
+				{SideSell, "bitcoin", 0.05, 42000, 1},
+			},
+			errors:  1,
+			ignored: 1,
+		},
+		{
+			name:   "coinbase legacy headers",
+			format: ImportFormatCoinbase,
+			data: `Timestamp,Transaction Type,Asset,Quantity Transacted,Spot Price Currency,Spot Price at Transaction,Subtotal,Total (inclusive of fees and/or spread),Fees and/or Spread,Notes
+2022-05-01 12:00:00 UTC,Buy,ETH,2,USD,2800,5600,5610,10,
+2022-05-02 12:00:00 UTC,Buy,ETH,1,EUR,2600,2600,2605,5,
+`,
+			want: []importedTrade{
+				{SideBuy, "ethereum", 2, 2800, 10},
+			},
+			errors: 1,
+		},
+		{
+			name:   "coinbase without a price column",
+			format: ImportFormatCoinbase,
+			data: `Timestamp,Transaction Type,Asset,Quantity Transacted,Fees and/or Spread
+2024-01-05T10:00:00Z,Buy,BTC,0.1,1
+`,
+			errors: 1,
+		},
+		{
+			name:   "native",
+			format: "auto",
+			data: `id,date,side,symbol,amount,price,fee,note
+tx-1,2023-06-01,buy,bitcoin,1,30000,5,first
+tx-2,2023-07-01,sell,bitcoin,-1,31000,0,negative amount
+`,
+			want: []importedTrade{
+				{SideBuy, "bitcoin", 1, 30000, 5},
+			},
+			errors: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseImport([]byte(tt.data), tt.format, "user")
+			if err != nil {
+				t.Fatalf("parseImport: %v", err)
+			}
+			if len(result.Errors) != tt.errors {
+				t.Errorf("errors = %q, want %d", result.Errors, tt.errors)
+			}
+			if result.Ignored != tt.ignored {
+				t.Errorf("ignored = %d, want %d", result.Ignored, tt.ignored)
+			}
+
+			var got []importedTrade
+			for _, tx := range result.Transactions {
+				got = append(got, importedTrade{tx.Side, tx.Symbol, tx.Amount, tx.Price, tx.Fee})
+				if tx.ID == "" || tx.CreatedBy != "user" || tx.Type != "personal" {
+					t.Errorf("transaction not assigned to the user: %+v", tx)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("transactions = %+v, want %+v", got, tt.want)
+			}
+			for idx := range got {
+				if got[idx] != tt.want[idx] {
+					t.Errorf("transaction %d = %+v, want %+v", idx, got[idx], tt.want[idx])
+				}
+			}
+		})
+	}
+}
+
+func TestParseImportRejectsMissingHeader(t *testing.T) {
+	for _, format := range []string{ImportFormatNative, ImportFormatBinance, ImportFormatCoinbase} {
+		if _, err := parseImport([]byte("just,some,text\n1,2,3\n"), format, "user"); err == nil {
+			t.Errorf("%s: expected an error without a header", format)
+		}
+	}
+}
+
+func TestMergeImport(t *testing.T) {
+	day := func(n int) time.Time {
+		return time.Date(2024, 1, n, 12, 0, 0, 0, time.UTC)
+	}
+	trade := func(id, side string, amount float64, at time.Time) *Transaction {
+		return &Transaction{ID: id, Side: side, Symbol: "bitcoin", Amount: amount, Price: 100, Timestamp: at}
+	}
+	existing := []*Transaction{
+		trade("a", SideBuy, 1, day(1)),
+		trade("b", SideSell, 0.5, day(3)),
+	}
+
+	tests := []struct {
+		name       string
+		imported   []*Transaction
+		accepted   []string
+		duplicates int
+		errors     int
+	}{
+		{
+			name:     "new trades",
+			imported: []*Transaction{trade("c", SideBuy, 2, day(4)), trade("d", SideSell, 1, day(5))},
+			accepted: []string{"c", "d"},
+		},
+		{
+			name:       "same ID",
+			imported:   []*Transaction{trade("a", SideBuy, 3, day(6))},
+			duplicates: 1,
+		},
+		{
+			name:       "same trade with another ID",
+			imported:   []*Transaction{trade("x", SideBuy, 1, day(1)), trade("y", SideBuy, 1, day(1))},
+			duplicates: 2,
+		},
+		{
+			name:     "oversell at the time",
+			imported: []*Transaction{trade("c", SideSell, 1.5, day(2)), trade("d", SideBuy, 5, day(4))},
+			accepted: []string{"d"},
+			errors:   1,
+		},
+		{
+			name:     "sell covered by an earlier import",
+			imported: []*Transaction{trade("d", SideSell, 1.5, day(5)), trade("c", SideBuy, 1, day(4))},
+			accepted: []string{"c", "d"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accepted, duplicates, errs := mergeImport(existing, tt.imported)
+			var ids []string
+			for _, tx := range accepted {
+				ids = append(ids, tx.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.accepted, ",") {
+				t.Errorf("accepted = %v, want %v", ids, tt.accepted)
+			}
+			if duplicates != tt.duplicates {
+				t.Errorf("duplicates = %d, want %d", duplicates, tt.duplicates)
+			}
+			if len(errs) != tt.errors {
+				t.Errorf("errors = %q, want %d", errs, tt.errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// When enabled, getCryptoPrice queries every healthy provider and takes the median
+	priceConsensusMode bool
+	// Maximum allowed deviation (in percent) of any source from the median price
+	priceDeviationThreshold = 5.0
+)
+
+// minConsensusSources is how many providers must answer to call a price a consensus
+const minConsensusSources = 2
+
+type sourcePrice struct {
+	Provider string
+	Price    *CryptoPrice
+}
+
+// getConsensusPrice queries all available providers in parallel and returns the
+// median price. When any source deviates from the median by more than
+// priceDeviationThreshold, the result is marked as disputed, and when fewer
+// than minConsensusSources answer it is marked as single-source.
+func getConsensusPrice(id string) (*CryptoPrice, error) {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []sourcePrice
+		lastErr error
+	)
+
+	for _, provider := range orderedProviders() {
+		if !provider.allow() {
+			continue
+		}
+
+		wg.Add(1)
+		go func(p *priceProvider) {
+			defer wg.Done()
+
+			start := time.Now()
+			price, err := p.Fetch(id)
+			p.record(time.Since(start), err)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				lastErr = err
+				return
+			}
+			results = append(results, sourcePrice{Provider: p.Name, Price: price})
+		}(provider)
+	}
+	wg.Wait()
+
+	if len(results) == 0 {
+		if lastErr == nil {
+			return nil, fmt.Errorf("all price providers are temporarily unavailable")
+		}
+		return nil, lastErr
+	}
+
+	return aggregatePrices(id, results), nil
+}
+
+func aggregatePrices(id string, results []sourcePrice) *CryptoPrice {
+	if len(results) < minConsensusSources {
+		single := *results[0].Price
+		single.Sources = 1
+		single.SingleSource = results[0].Provider
+		log.Printf("Only %s answered for %s, price not cross-checked", results[0].Provider, id)
+		return &single
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Price.Price < results[j].Price.Price
+	})
+
+	var median float64
+	n := len(results)
+	if n%2 == 1 {
+		median = results[n/2].Price.Price
+	} else {
+		median = (results[n/2-1].Price.Price + results[n/2].Price.Price) / 2
+	}
+
+	// Use the source closest to the median for the remaining fields
+	closest := results[0]
+	for _, r := range results[1:] {
+		if abs(r.Price.Price-median) < abs(closest.Price.Price-median) {
+			closest = r
+		}
+	}
+
+	aggregated := *closest.Price
+	aggregated.Price = median
+	aggregated.Sources = n
+
+	// Binance doesn't report market cap, so borrow it from another source
+	if aggregated.MarketCap == 0 {
+		for _, r := range results {
+			if r.Price.MarketCap > 0 {
+				aggregated.MarketCap = r.Price.MarketCap
+				break
+			}
+		}
+	}
+
+	if median <= 0 {
+		return &aggregated
+	}
+
+	var outliers []string
+	for _, r := range results {
+		deviation := abs(r.Price.Price-median) / median * 100
+		if deviation > priceDeviationThreshold {
+			outliers = append(outliers, fmt.Sprintf("%s $%.6g (%.1f%%)", r.Provider, r.Price.Price, deviation))
+		}
+	}
+
+	if len(outliers) > 0 {
+		aggregated.Disputed = true
+
+		var all []string
+		for _, r := range results {
+			all = append(all, fmt.Sprintf("%s=$%.6g", r.Provider, r.Price.Price))
+		}
+		log.Printf("Price discrepancy for %s: median $%.6g, outliers: %s (all sources: %s)",
+			id, median, strings.Join(outliers, ", "), strings.Join(all, ", "))
+	}
+
+	return &aggregated
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestAggregatePrices(t *testing.T) {
+	source := func(provider string, price float64) sourcePrice {
+		return sourcePrice{Provider: provider, Price: &CryptoPrice{Price: price}}
+	}
+
+	tests := []struct {
+		name     string
+		results  []sourcePrice
+		price    float64
+		sources  int
+		disputed bool
+		single   string
+	}{
+		{"single source", []sourcePrice{source("Binance", 100)}, 100, 1, false, "Binance"},
+		{"two sources", []sourcePrice{source("Binance", 100), source("CoinGecko", 102)}, 101, 2, false, ""},
+		{"median of three", []sourcePrice{source("CoinGecko", 101), source("Binance", 100), source("CoinCap", 99)}, 100, 3, false, ""},
+		{"outlier", []sourcePrice{source("CoinGecko", 100), source("Binance", 101), source("CoinCap", 120)}, 101, 3, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := aggregatePrices("bitcoin", tt.results)
+			if got.Price != tt.price || got.Sources != tt.sources || got.Disputed != tt.disputed || got.SingleSource != tt.single {
+				t.Errorf("got price %v, %d sources, disputed %v, single %q; want %v, %d, %v, %q",
+					got.Price, got.Sources, got.Disputed, got.SingleSource, tt.price, tt.sources, tt.disputed, tt.single)
+			}
+		})
+	}
+}
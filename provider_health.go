@@ -0,0 +1,224 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Errors that mean the provider answered correctly but doesn't know the coin.
+// They don't count against the provider's health.
+var (
+	errCoinNotFound    = errors.New("not found")
+	errUnsupportedCoin = errors.New("unsupported cryptocurrency")
+)
+
+const (
+	breakerFailureThreshold = 3               // Consecutive failures before the breaker opens
+	breakerOpenDuration     = 2 * time.Minute // How long to skip a provider before probing it again
+	healthEWMAWeight        = 0.2             // Weight of the newest sample in latency/success averages
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (b breakerState) String() string {
+	switch b {
+	case breakerOpen:
+		return "Open"
+	case breakerHalfOpen:
+		return "Half-open"
+	default:
+		return "Closed"
+	}
+}
+
+// priceProvider is one entry of the fallback chain used by getCryptoPrice,
+// together with its circuit breaker and health statistics.
+type priceProvider struct {
+	Name  string
+	Fetch func(id string) (*CryptoPrice, error)
+
+	mu                  sync.Mutex
+	state               breakerState
+	consecutiveFailures int
+	openedAt            time.Time
+	probeInFlight       bool
+	avgLatency          time.Duration
+	successRate         float64
+	totalCalls          int
+	totalFailures       int
+	lastError           string
+	lastErrorAt         time.Time
+}
+
+func newPriceProvider(name string, fetch func(id string) (*CryptoPrice, error)) *priceProvider {
+	return &priceProvider{
+		Name:        name,
+		Fetch:       fetch,
+		successRate: 1,
+	}
+}
+
+// allow reports whether a request may be sent to the provider. An open breaker
+// lets a single probe through once breakerOpenDuration has passed.
+func (p *priceProvider) allow() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch p.state {
+	case breakerOpen:
+		if time.Since(p.openedAt) < breakerOpenDuration {
+			return false
+		}
+		p.state = breakerHalfOpen
+		p.probeInFlight = true
+		return true
+	case breakerHalfOpen:
+		if p.probeInFlight {
+			return false
+		}
+		p.probeInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates the breaker and health statistics with the result of a call.
+func (p *priceProvider) record(latency time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.probeInFlight = false
+	p.totalCalls++
+
+	if err != nil && !isProviderFailure(err) {
+		// The provider is healthy, it just doesn't list this coin
+		if p.state == breakerHalfOpen {
+			p.state = breakerClosed
+		}
+		return
+	}
+
+	if p.avgLatency == 0 {
+		p.avgLatency = latency
+	} else {
+		p.avgLatency = time.Duration(healthEWMAWeight*float64(latency) + (1-healthEWMAWeight)*float64(p.avgLatency))
+	}
+
+	if err == nil {
+		p.successRate = healthEWMAWeight + (1-healthEWMAWeight)*p.successRate
+		p.consecutiveFailures = 0
+		p.state = breakerClosed
+		return
+	}
+
+	p.successRate = (1 - healthEWMAWeight) * p.successRate
+	p.totalFailures++
+	p.consecutiveFailures++
+	p.lastError = err.Error()
+	p.lastErrorAt = time.Now()
+
+	if p.state == breakerHalfOpen || p.consecutiveFailures >= breakerFailureThreshold {
+		p.state = breakerOpen
+		p.openedAt = time.Now()
+	}
+}
+
+// score ranks providers by success rate first and latency second. Success
+// rates are bucketed so small fluctuations don't reshuffle the chain.
+func (p *priceProvider) score() (float64, time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return math.Round(p.successRate*20) / 20, p.avgLatency
+}
+
+func isProviderFailure(err error) bool {
+	return !errors.Is(err, errCoinNotFound) && !errors.Is(err, errUnsupportedCoin)
+}
+
+// orderedProviders returns the fallback chain sorted by recent health.
+func orderedProviders() []*priceProvider {
+	ordered := make([]*priceProvider, len(priceProviders))
+	copy(ordered, priceProviders)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		iRate, iLatency := ordered[i].score()
+		jRate, jLatency := ordered[j].score()
+		if iRate != jRate {
+			return iRate > jRate
+		}
+		// Only reorder on latency when the difference is noticeable
+		return iLatency+100*time.Millisecond < jLatency
+	})
+	return ordered
+}
+
+// providerHealthValue describes a provider's breaker and health statistics.
+func providerHealthValue(p *priceProvider) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	value := fmt.Sprintf(
+		"State: %s\n"+
+			"Success rate: %.0f%%\n"+
+			"Avg latency: %s\n"+
+			"Calls: %d (%d failed)\n"+
+			"Consecutive failures: %d",
+		p.state,
+		p.successRate*100,
+		p.avgLatency.Round(time.Millisecond),
+		p.totalCalls,
+		p.totalFailures,
+		p.consecutiveFailures,
+	)
+	if p.state == breakerOpen {
+		value += fmt.Sprintf("\nRetry after: %s", p.openedAt.Add(breakerOpenDuration).Format("15:04:05 MST"))
+	}
+	if p.lastError != "" {
+		value += fmt.Sprintf("\nLast error: %s (%s)", p.lastError, p.lastErrorAt.Format("15:04:05"))
+	}
+	return value
+}
+
+func handleProvidersCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var fields []*discordgo.MessageEmbedField
+	for rank, p := range orderedProviders() {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("%d. %s", rank+1, p.Name),
+			Value: providerHealthValue(p),
+		})
+	}
+	fields = append(fields, &discordgo.MessageEmbedField{
+		Name:  coinGeckoMarketsProvider.Name + " (batched market data, not in the price chain)",
+		Value: providerHealthValue(coinGeckoMarketsProvider),
+	})
+
+	embed := &discordgo.MessageEmbed{
+		Title:  "Price Provider Health",
+		Color:  0x00ff00,
+		Fields: fields,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Providers are tried in the order shown • " + time.Now().Format("2006-01-02 15:04:05 MST"),
+		},
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
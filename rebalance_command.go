@@ -0,0 +1,439 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// Coins beyond this many are grouped into "Other" in the allocation chart
+	maxAllocationSlices = 7
+	// Trades smaller than this are left out of the rebalancing plan
+	defaultMinRebalanceTrade = 10.0
+)
+
+// allocationEntry is one coin's current and target share of a portfolio.
+type allocationEntry struct {
+	Symbol  string
+	Amount  float64
+	Price   float64
+	Value   float64
+	Percent float64
+	Target  float64 // Percent, 0 when no target is set
+}
+
+// computeAllocation values each holding and coin with a target at current
+// prices, largest first. Coins whose price can't be fetched are returned separately.
+func computeAllocation(holdings []*Holding, targets map[string]float64) ([]allocationEntry, float64, []string) {
+	amounts := make(map[string]float64)
+	for _, h := range holdings {
+		amounts[h.Symbol] += h.Amount
+	}
+	for symbol := range targets {
+		if _, exists := amounts[symbol]; !exists {
+			amounts[symbol] = 0
+		}
+	}
+
+	var entries []allocationEntry
+	var missing []string
+	var total float64
+	for symbol, amount := range amounts {
+		price, err := getCryptoPrice(symbol)
+		if err != nil {
+			log.Printf("Error getting price for %s: %v", symbol, err)
+			missing = append(missing, strings.ToUpper(symbol))
+			continue
+		}
+		value := amount * price.Price
+		total += value
+		entries = append(entries, allocationEntry{
+			Symbol: symbol,
+			Amount: amount,
+			Price:  price.Price,
+			Value:  value,
+			Target: targets[symbol],
+		})
+	}
+
+	for idx := range entries {
+		if total > 0 {
+			entries[idx].Percent = entries[idx].Value / total * 100
+		}
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		if entries[a].Value != entries[b].Value {
+			return entries[a].Value > entries[b].Value
+		}
+		return entries[a].Symbol < entries[b].Symbol
+	})
+	sort.Strings(missing)
+	return entries, total, missing
+}
+
+// createAllocationView builds the allocation embed and its donut chart.
+func createAllocationView(entries []allocationEntry, total float64, missing []string, username string) (*discordgo.MessageEmbed, []byte) {
+	embed := &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("%s's Allocation", username),
+		Color: 0x3498db,
+	}
+
+	// Group the smallest positions so the chart stays readable
+	var values []float64
+	var lines []string
+	var otherValue, otherPercent float64
+	for idx, e := range entries {
+		if e.Value <= 0 {
+			continue
+		}
+		if idx >= maxAllocationSlices {
+			otherValue += e.Value
+			otherPercent += e.Percent
+			continue
+		}
+
+		line := fmt.Sprintf("%s **%s** %.1f%% • %s", chartLegendEmojis[len(values)], strings.ToUpper(e.Symbol), e.Percent, formatUSD(e.Value))
+		if e.Target > 0 {
+			line += fmt.Sprintf(" • target %.1f%% (%+.1f)", e.Target, e.Percent-e.Target)
+		}
+		lines = append(lines, line)
+		values = append(values, e.Value)
+	}
+	if otherValue > 0 {
+		lines = append(lines, fmt.Sprintf("%s **Other** %.1f%% • %s", chartLegendEmojis[len(values)], otherPercent, formatUSD(otherValue)))
+		values = append(values, otherValue)
+	}
+
+	// Targets for coins that aren't held yet
+	for _, e := range entries {
+		if e.Value <= 0 && e.Target > 0 {
+			lines = append(lines, fmt.Sprintf("▫️ **%s** 0.0%% • target %.1f%%", strings.ToUpper(e.Symbol), e.Target))
+		}
+	}
+
+	if len(lines) == 0 {
+		embed.Description = "You have no personal holdings."
+		return embed, nil
+	}
+	embed.Description = truncateFieldValue(strings.Join(lines, "\n"))
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text: fmt.Sprintf("Total %s • Set targets with /rebalance target", formatUSD(total)),
+	}
+	if len(missing) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "⚠️ Prices Unavailable",
+			Value: strings.Join(missing, ", "),
+		})
+	}
+
+	chart, err := renderDonutChart(values, 400)
+	if err != nil {
+		log.Printf("Error rendering allocation chart: %v", err)
+		return embed, nil
+	}
+	embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://allocation.png"}
+	return embed, chart
+}
+
+func handleAllocationView(s *discordgo.Session, i *discordgo.InteractionCreate, holdings []*Holding) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	portMutex.RLock()
+	targets := targetAllocationsLocked(i.Member.User.ID)
+	portMutex.RUnlock()
+
+	entries, total, missing := computeAllocation(holdings, targets)
+	embed, chart := createAllocationView(entries, total, missing, i.Member.User.Username)
+
+	edit := &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	}
+	if chart != nil {
+		edit.Files = []*discordgo.File{{
+			Name:        "allocation.png",
+			ContentType: "image/png",
+			Reader:      bytes.NewReader(chart),
+		}}
+	}
+	s.InteractionResponseEdit(i.Interaction, edit)
+}
+
+// targetAllocationsLocked returns a copy of the user's targets.
+// The caller must hold portMutex.
+func targetAllocationsLocked(userID string) map[string]float64 {
+	targets := make(map[string]float64)
+	if portfolio, exists := portfolios[userID]; exists {
+		for symbol, percent := range portfolio.TargetAllocations {
+			targets[symbol] = percent
+		}
+	}
+	return targets
+}
+
+func sumTargets(targets map[string]float64) float64 {
+	var sum float64
+	for _, percent := range targets {
+		sum += percent
+	}
+	return sum
+}
+
+// rebalanceTrade is a buy (positive Value) or sell (negative Value) that
+// moves a coin to its target weight.
+type rebalanceTrade struct {
+	Symbol string
+	Amount float64
+	Value  float64
+}
+
+// planRebalance returns the trades that bring every coin to its target,
+// skipping trades worth less than minTrade.
+func planRebalance(entries []allocationEntry, total, minTrade float64) []rebalanceTrade {
+	var trades []rebalanceTrade
+	for _, e := range entries {
+		diff := total*e.Target/100 - e.Value
+		if math.Abs(diff) < minTrade || e.Price <= 0 {
+			continue
+		}
+		trades = append(trades, rebalanceTrade{
+			Symbol: e.Symbol,
+			Amount: diff / e.Price,
+			Value:  diff,
+		})
+	}
+
+	// Sells first, since they fund the buys
+	sort.Slice(trades, func(a, b int) bool {
+		return trades[a].Value < trades[b].Value
+	})
+	return trades
+}
+
+func handleRebalanceCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return
+	}
+
+	switch options[0].Name {
+	case "plan":
+		handleRebalancePlan(s, i, options[0].Options)
+	case "target":
+		handleRebalanceTarget(s, i, options[0].Options)
+	case "clear":
+		handleRebalanceClear(s, i)
+	}
+}
+
+func respondRebalance(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func handleRebalanceTarget(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	var symbol string
+	var percent float64
+	for _, opt := range options {
+		switch opt.Name {
+		case "symbol":
+			symbol = parseGeckoID(opt.StringValue())
+		case "percent":
+			percent = opt.FloatValue()
+		}
+	}
+	if percent < 0 || percent > 100 {
+		respondRebalance(s, i, "⚠️ Targets must be between 0% and 100%")
+		return
+	}
+
+	userID := i.Member.User.ID
+	portMutex.Lock()
+	targets := targetAllocationsLocked(userID)
+	if percent == 0 {
+		delete(targets, symbol)
+	} else {
+		targets[symbol] = percent
+	}
+	sum := sumTargets(targets)
+	if sum > 100.0001 {
+		portMutex.Unlock()
+		respondRebalance(s, i, fmt.Sprintf("⚠️ That would bring your targets to %.1f%%, they can't exceed 100%%", sum))
+		return
+	}
+	getOrCreatePortfolioLocked(userID).TargetAllocations = targets
+	portMutex.Unlock()
+
+	if err := savePortfolio(userID); err != nil {
+		log.Printf("Error saving portfolios: %v", err)
+	}
+
+	content := fmt.Sprintf("🎯 Target for %s set to %.1f%%", strings.ToUpper(symbol), percent)
+	if percent == 0 {
+		content = fmt.Sprintf("🎯 Removed the target for %s", strings.ToUpper(symbol))
+	}
+	content += fmt.Sprintf("\nTargets total %.1f%%", sum)
+	if sum < 99.9999 {
+		content += fmt.Sprintf(", %.1f%% left to assign before you can rebalance", 100-sum)
+	}
+	respondRebalance(s, i, content)
+}
+
+func handleRebalanceClear(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := i.Member.User.ID
+	portMutex.Lock()
+	if portfolio, exists := portfolios[userID]; exists {
+		portfolio.TargetAllocations = nil
+	}
+	portMutex.Unlock()
+
+	if err := savePortfolio(userID); err != nil {
+		log.Printf("Error saving portfolios: %v", err)
+	}
+	respondRebalance(s, i, "🎯 Cleared all your target allocations")
+}
+
+func handleRebalancePlan(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	minTrade := defaultMinRebalanceTrade
+	for _, opt := range options {
+		if opt.Name == "min_trade" {
+			minTrade = opt.FloatValue()
+		}
+	}
+
+	userID := i.Member.User.ID
+	portMutex.RLock()
+	targets := targetAllocationsLocked(userID)
+	holdings := holdingsList(personalTransactionsLocked(userID), costBasisMethodLocked(userID))
+	portMutex.RUnlock()
+
+	if len(targets) == 0 {
+		respondRebalance(s, i, "You have no target allocations. Set them with `/rebalance target`.")
+		return
+	}
+	if sum := sumTargets(targets); math.Abs(sum-100) > 0.0001 {
+		respondRebalance(s, i, fmt.Sprintf("⚠️ Your targets total %.1f%%, they must add up to 100%% to rebalance", sum))
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	entries, total, missing := computeAllocation(holdings, targets)
+	if len(missing) > 0 {
+		content := fmt.Sprintf("⚠️ Couldn't get prices for %s, try again later", strings.Join(missing, ", "))
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+		return
+	}
+	if total <= 0 {
+		content := "You have no personal holdings to rebalance."
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+		return
+	}
+
+	trades := planRebalance(entries, total, minTrade)
+	embed := &discordgo.MessageEmbed{
+		Title: "⚖️ Rebalancing Plan",
+		Color: 0x3498db,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Portfolio value %s • trades under %s skipped • fees not included", formatUSD(total), formatUSD(minTrade)),
+		},
+	}
+
+	if len(trades) == 0 {
+		embed.Description = "✅ Your portfolio is already on target."
+	} else {
+		var lines []string
+		for _, t := range trades {
+			side := "🟢 Buy"
+			if t.Value < 0 {
+				side = "🔴 Sell"
+			}
+			lines = append(lines, fmt.Sprintf("%s %.6g %s (~%s)", side, math.Abs(t.Amount), strings.ToUpper(t.Symbol), formatUSD(math.Abs(t.Value))))
+		}
+		embed.Description = truncateFieldValue(strings.Join(lines, "\n"))
+	}
+
+	var drift []string
+	for _, e := range entries {
+		drift = append(drift, fmt.Sprintf("**%s** %.1f%% → %.1f%%", strings.ToUpper(e.Symbol), e.Percent, e.Target))
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:  "Current → Target",
+		Value: truncateFieldValue(strings.Join(drift, "\n")),
+	})
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+}
+
+func rebalanceCommandOptions() []*discordgo.ApplicationCommandOption {
+	zero := 0.0
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "plan",
+			Description: "Show the trades needed to return to your targets",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionNumber,
+					Name:        "min_trade",
+					Description: "Skip trades smaller than this many USD (default 10)",
+					Required:    false,
+					MinValue:    &zero,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "target",
+			Description: "Set a coin's target share of your portfolio",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "symbol",
+					Description:  "Cryptocurrency",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionNumber,
+					Name:        "percent",
+					Description: "Target percentage, 0 removes the target",
+					Required:    true,
+					MinValue:    &zero,
+					MaxValue:    100,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "clear",
+			Description: "Remove all your targets",
+		},
+	}
+}
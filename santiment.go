@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const santimentAPIURL = "https://api.santiment.net/graphql"
+
+var (
+	santimentMetricPattern   = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	santimentIntervalPattern = regexp.MustCompile(`^[0-9]+[smhdw]$`)
+)
+
+// SantimentClient is a minimal GraphQL client for the Santiment API.
+type SantimentClient struct {
+	Endpoint   string
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+type TimeseriesPoint struct {
+	Datetime time.Time `json:"datetime"`
+	Value    float64   `json:"value"`
+}
+
+// SentimentSnapshot holds the averaged social metrics for one coin.
+type SentimentSnapshot struct {
+	Slug         string
+	Positive     float64
+	Negative     float64
+	SocialVolume float64
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func newSantimentClient(apiKey string) *SantimentClient {
+	return &SantimentClient{
+		Endpoint:   santimentAPIURL,
+		APIKey:     apiKey,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// query sends a GraphQL query and decodes the "data" object into out.
+func (c *SantimentClient) query(query string, variables map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return fmt.Errorf("failed to encode query: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Apikey "+c.APIKey)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 429 {
+		return fmt.Errorf("rate limit exceeded")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("non-200 response from API: %d", resp.StatusCode)
+	}
+
+	var gqlResp graphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&gqlResp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(gqlResp.Errors) > 0 {
+		var messages []string
+		for _, e := range gqlResp.Errors {
+			messages = append(messages, e.Message)
+		}
+		return fmt.Errorf("santiment API error: %s", strings.Join(messages, "; "))
+	}
+
+	if err := json.Unmarshal(gqlResp.Data, out); err != nil {
+		return fmt.Errorf("failed to parse response data: %w", err)
+	}
+	return nil
+}
+
+// GetMetrics fetches several timeseries metrics for one slug in a single
+// request. Every getMetric field is aliased with its metric name so the
+// results don't collide in the response.
+func (c *SantimentClient) GetMetrics(slug string, metrics []string, from, to time.Time, interval string) (map[string][]TimeseriesPoint, error) {
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("no metrics requested")
+	}
+	if !santimentIntervalPattern.MatchString(interval) {
+		return nil, fmt.Errorf("invalid interval: %q", interval)
+	}
+
+	var fields strings.Builder
+	for _, metric := range metrics {
+		if !santimentMetricPattern.MatchString(metric) {
+			return nil, fmt.Errorf("invalid metric name: %q", metric)
+		}
+		fmt.Fprintf(&fields, `
+	%s: getMetric(metric: "%s") {
+		timeseriesData(slug: $slug, from: $from, to: $to, interval: "%s") {
+			datetime
+			value
+		}
+	}`, metric, metric, interval)
+	}
+
+	query := fmt.Sprintf("query($slug: String!, $from: DateTime!, $to: DateTime!) {%s\n}", fields.String())
+	variables := map[string]interface{}{
+		"slug": slug,
+		"from": from.UTC().Format(time.RFC3339),
+		"to":   to.UTC().Format(time.RFC3339),
+	}
+
+	var data map[string]struct {
+		TimeseriesData []TimeseriesPoint `json:"timeseriesData"`
+	}
+	if err := c.query(query, variables, &data); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]TimeseriesPoint, len(metrics))
+	for _, metric := range metrics {
+		result[metric] = data[metric].TimeseriesData
+	}
+	return result, nil
+}
+
+// GetSentiment returns the average positive/negative sentiment and social
+// volume for a coin over the given period.
+func (c *SantimentClient) GetSentiment(slug string, from, to time.Time) (*SentimentSnapshot, error) {
+	series, err := c.GetMetrics(slug, []string{"sentiment_positive_total", "sentiment_negative_total", "social_volume_total"}, from, to, "1d")
+	if err != nil {
+		return nil, err
+	}
+
+	average := func(points []TimeseriesPoint) (float64, bool) {
+		if len(points) == 0 {
+			return 0, false
+		}
+		var total float64
+		for _, p := range points {
+			total += p.Value
+		}
+		return total / float64(len(points)), true
+	}
+
+	positive, okPositive := average(series["sentiment_positive_total"])
+	negative, okNegative := average(series["sentiment_negative_total"])
+	volume, okVolume := average(series["social_volume_total"])
+	if !okPositive || !okNegative || !okVolume {
+		return nil, fmt.Errorf("insufficient sentiment data for %s", slug)
+	}
+
+	return &SentimentSnapshot{
+		Slug:         slug,
+		Positive:     positive,
+		Negative:     negative,
+		SocialVolume: volume,
+	}, nil
+}
+
+// Classify turns the snapshot into a human readable sentiment label based on
+// the share of positive mentions.
+func (s *SentimentSnapshot) Classify() string {
+	if s.Positive+s.Negative == 0 {
+		return "Neutral 🟡"
+	}
+
+	ratio := s.Positive / (s.Positive + s.Negative)
+	switch {
+	case ratio >= 0.65:
+		return "Extreme Greed 🟢"
+	case ratio >= 0.55:
+		return "Greed ✅"
+	case ratio <= 0.35:
+		return "Extreme Fear 🔴"
+	case ratio <= 0.45:
+		return "Fear ⚠️"
+	default:
+		return "Neutral 🟡"
+	}
+}
+
+var santimentClient *SantimentClient
+
+// getMarketSentiment returns the social sentiment label for a coin over the last week.
+func getMarketSentiment(geckoID string) (string, error) {
+	now := time.Now()
+	snapshot, err := santimentClient.GetSentiment(convertToSantimentSlug(geckoID), now.AddDate(0, 0, -7), now)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s (social volume %.0f/day)", snapshot.Classify(), snapshot.SocialVolume), nil
+}
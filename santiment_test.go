@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordedRequest holds the parts of the last request the test server received.
+type recordedRequest struct {
+	mu     sync.Mutex
+	header http.Header
+	body   graphQLRequest
+}
+
+func (r *recordedRequest) Header() http.Header {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.header
+}
+
+func (r *recordedRequest) Body() graphQLRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.body
+}
+
+func newTestSantimentServer(t *testing.T, handler func(w http.ResponseWriter, req graphQLRequest)) (*SantimentClient, *recordedRequest) {
+	t.Helper()
+
+	recorded := &recordedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			// t.Fatalf must not be called outside the test goroutine
+			t.Errorf("invalid request body: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		recorded.mu.Lock()
+		recorded.header = r.Header.Clone()
+		recorded.body = req
+		recorded.mu.Unlock()
+
+		handler(w, req)
+	}))
+	t.Cleanup(server.Close)
+
+	client := newSantimentClient("test-key")
+	client.Endpoint = server.URL
+	return client, recorded
+}
+
+func TestSantimentGetMetricsUsesAliases(t *testing.T) {
+	client, lastRequest := newTestSantimentServer(t, func(w http.ResponseWriter, req graphQLRequest) {
+		w.Write([]byte(`{"data": {
+			"sentiment_positive_total": {"timeseriesData": [{"datetime": "2024-01-01T00:00:00Z", "value": 10}]},
+			"social_volume_total": {"timeseriesData": [{"datetime": "2024-01-01T00:00:00Z", "value": 300}, {"datetime": "2024-01-02T00:00:00Z", "value": 500}]}
+		}}`))
+	})
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	series, err := client.GetMetrics("ethereum", []string{"sentiment_positive_total", "social_volume_total"}, from, from.AddDate(0, 0, 2), "1d")
+	if err != nil {
+		t.Fatalf("GetMetrics returned error: %v", err)
+	}
+
+	gotQuery := lastRequest.Body()
+	if got := lastRequest.Header().Get("Authorization"); got != "Apikey test-key" {
+		t.Errorf("Authorization header = %q, want %q", got, "Apikey test-key")
+	}
+	for _, alias := range []string{`sentiment_positive_total: getMetric(metric: "sentiment_positive_total")`, `social_volume_total: getMetric(metric: "social_volume_total")`} {
+		if !strings.Contains(gotQuery.Query, alias) {
+			t.Errorf("query is missing alias %q:\n%s", alias, gotQuery.Query)
+		}
+	}
+	if gotQuery.Variables["slug"] != "ethereum" {
+		t.Errorf("slug variable = %v, want ethereum", gotQuery.Variables["slug"])
+	}
+
+	if len(series["sentiment_positive_total"]) != 1 || series["sentiment_positive_total"][0].Value != 10 {
+		t.Errorf("unexpected positive series: %+v", series["sentiment_positive_total"])
+	}
+	if len(series["social_volume_total"]) != 2 || series["social_volume_total"][1].Value != 500 {
+		t.Errorf("unexpected volume series: %+v", series["social_volume_total"])
+	}
+}
+
+func TestSantimentGetMetricsRejectsInvalidInput(t *testing.T) {
+	client := newSantimentClient("")
+	now := time.Now()
+
+	if _, err := client.GetMetrics("bitcoin", []string{`bad") { x }`}, now, now, "1d"); err == nil {
+		t.Error("expected error for invalid metric name")
+	}
+	if _, err := client.GetMetrics("bitcoin", []string{"social_volume_total"}, now, now, "1 day"); err == nil {
+		t.Error("expected error for invalid interval")
+	}
+}
+
+func TestSantimentGraphQLErrors(t *testing.T) {
+	client, _ := newTestSantimentServer(t, func(w http.ResponseWriter, req graphQLRequest) {
+		w.Write([]byte(`{"data": null, "errors": [{"message": "unauthorized"}]}`))
+	})
+
+	_, err := client.GetMetrics("bitcoin", []string{"social_volume_total"}, time.Now(), time.Now(), "1d")
+	if err == nil || !strings.Contains(err.Error(), "unauthorized") {
+		t.Fatalf("expected GraphQL error to be surfaced, got %v", err)
+	}
+}
+
+func TestSantimentGetSentiment(t *testing.T) {
+	client, _ := newTestSantimentServer(t, func(w http.ResponseWriter, req graphQLRequest) {
+		w.Write([]byte(`{"data": {
+			"sentiment_positive_total": {"timeseriesData": [{"datetime": "2024-01-01T00:00:00Z", "value": 70}, {"datetime": "2024-01-02T00:00:00Z", "value": 90}]},
+			"sentiment_negative_total": {"timeseriesData": [{"datetime": "2024-01-01T00:00:00Z", "value": 20}]},
+			"social_volume_total": {"timeseriesData": [{"datetime": "2024-01-01T00:00:00Z", "value": 1000}]}
+		}}`))
+	})
+
+	snapshot, err := client.GetSentiment("solana", time.Now().AddDate(0, 0, -2), time.Now())
+	if err != nil {
+		t.Fatalf("GetSentiment returned error: %v", err)
+	}
+	if snapshot.Positive != 80 || snapshot.Negative != 20 || snapshot.SocialVolume != 1000 {
+		t.Errorf("unexpected snapshot: %+v", snapshot)
+	}
+	if got := snapshot.Classify(); !strings.HasPrefix(got, "Extreme Greed") {
+		t.Errorf("Classify() = %q, want Extreme Greed", got)
+	}
+}
+
+func TestSantimentGetSentimentMissingData(t *testing.T) {
+	client, _ := newTestSantimentServer(t, func(w http.ResponseWriter, req graphQLRequest) {
+		w.Write([]byte(`{"data": {"sentiment_positive_total": {"timeseriesData": []}}}`))
+	})
+
+	if _, err := client.GetSentiment("bitcoin", time.Now(), time.Now()); err == nil {
+		t.Error("expected error when metrics are missing")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// GuildSettings holds per-guild configuration for scheduled posts and alerts.
+type GuildSettings struct {
+	GuildID string `json:"guild_id"`
+
+	// Daily Fear & Greed auto-post
+	FearGreedChannelID  string    `json:"fear_greed_channel_id,omitempty"`
+	FearGreedLastPosted time.Time `json:"fear_greed_last_posted"`
+
+	FearGreedAlert *FearGreedAlert `json:"fear_greed_alert,omitempty"`
+
+	// Live ticker message, see /ticker
+	Ticker *TickerSettings `json:"ticker,omitempty"`
+
+	// Scheduled market summary, see /digest
+	Digest *DigestSettings `json:"digest,omitempty"`
+
+	// Role allowed to manage price bots, alerts, groups and the server watchlist, see /permissions
+	ManagerRoleID string `json:"manager_role_id,omitempty"`
+}
+
+// FearGreedAlert fires when the index enters an extreme zone.
+type FearGreedAlert struct {
+	ChannelID      string `json:"channel_id"`
+	FearThreshold  int    `json:"fear_threshold"`
+	GreedThreshold int    `json:"greed_threshold"`
+	LastZone       string `json:"last_zone,omitempty"`
+}
+
+var (
+	guildSettings = make(map[string]*GuildSettings)
+	settingsMutex sync.RWMutex
+)
+
+// updateGuildSettings applies fn to the guild's settings while holding the lock,
+// creating the settings if they don't exist yet, and saves them.
+func updateGuildSettings(guildID string, fn func(settings *GuildSettings)) {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+
+	settings, exists := guildSettings[guildID]
+	if !exists {
+		settings = &GuildSettings{GuildID: guildID}
+		guildSettings[guildID] = settings
+	}
+	fn(settings)
+
+	if err := writeGuildSettingsLocked([]string{guildID}); err != nil {
+		log.Printf("Error saving settings for guild %s: %v", guildID, err)
+	}
+}
+
+// forEachGuildSettings calls fn for every guild while holding the lock, then
+// saves all settings.
+func forEachGuildSettings(fn func(settings *GuildSettings)) {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+
+	guildIDs := make([]string, 0, len(guildSettings))
+	for guildID, settings := range guildSettings {
+		fn(settings)
+		guildIDs = append(guildIDs, guildID)
+	}
+
+	if err := writeGuildSettingsLocked(guildIDs); err != nil {
+		log.Printf("Error saving guild settings: %v", err)
+	}
+}
+
+// writeGuildSettingsLocked stores the given guilds' settings in one
+// transaction. The caller must hold settingsMutex.
+func writeGuildSettingsLocked(guildIDs []string) error {
+	return store.Update(func(tx StoreTx) error {
+		for _, guildID := range guildIDs {
+			if err := putJSON(tx, bucketGuildSettings, guildID, guildSettings[guildID]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// PortfolioSnapshot is a portfolio's value at the end of one day.
+type PortfolioSnapshot struct {
+	Date     time.Time               `json:"date"`
+	Value    float64                 `json:"value"`
+	Cost     float64                 `json:"cost"`
+	BTCPrice float64                 `json:"btc_price"` // For comparing against holding BTC
+	Coins    map[string]CoinSnapshot `json:"coins"`
+}
+
+type CoinSnapshot struct {
+	Amount float64 `json:"amount"`
+	Price  float64 `json:"price"`
+	Value  float64 `json:"value"`
+}
+
+var (
+	// Snapshots keyed by user ID, or "fund:<id>" for group funds
+	snapshots     = make(map[string][]PortfolioSnapshot)
+	snapshotMutex sync.RWMutex
+	// Legacy file, imported into the store on first start
+	snapshotFile = filepath.Join(filepath.Dir(saveFile), "portfolio_snapshots.json")
+)
+
+func fundSnapshotKey(fundID string) string {
+	return "fund:" + fundID
+}
+
+// importLegacySnapshots copies portfolio_snapshots.json into the store.
+func importLegacySnapshots(tx StoreTx) error {
+	data, err := os.ReadFile(snapshotFile)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var legacy map[string][]PortfolioSnapshot
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return fmt.Errorf("error reading %s: %w", snapshotFile, err)
+	}
+	for key, history := range legacy {
+		if err := putJSON(tx, bucketSnapshots, key, history); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSnapshots stores the histories for the given keys in one transaction.
+// The caller must hold snapshotMutex.
+func writeSnapshots(keys []string) error {
+	return store.Update(func(tx StoreTx) error {
+		for _, key := range keys {
+			if err := putJSON(tx, bucketSnapshots, key, snapshots[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// snapshotDay truncates a time to its UTC day.
+func snapshotDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// takeSnapshot values holdings at the given prices. It returns false when a
+// price is missing, since a partial value would show up as a fake loss.
+func takeSnapshot(holdings []*Holding, prices map[string]float64, now time.Time) (PortfolioSnapshot, bool) {
+	snapshot := PortfolioSnapshot{
+		Date:     now,
+		BTCPrice: prices["bitcoin"],
+		Coins:    make(map[string]CoinSnapshot),
+	}
+	for _, h := range holdings {
+		price, ok := prices[h.Symbol]
+		if !ok {
+			return snapshot, false
+		}
+		value := price * h.Amount
+		snapshot.Value += value
+		snapshot.Cost += h.CostBasis
+		snapshot.Coins[h.Symbol] = CoinSnapshot{Amount: h.Amount, Price: price, Value: value}
+	}
+	return snapshot, true
+}
+
+func runSnapshotScheduler() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		snapshotPortfolios()
+		<-ticker.C
+	}
+}
+
+// snapshotPortfolios records today's value of every portfolio and fund that
+// hasn't been snapshotted yet today.
+func snapshotPortfolios() {
+	now := time.Now().UTC()
+	today := snapshotDay(now)
+
+	snapshotMutex.RLock()
+	done := func(key string) bool {
+		history := snapshots[key]
+		return len(history) > 0 && !snapshotDay(history[len(history)-1].Date).Before(today)
+	}
+
+	pending := make(map[string][]*Holding)
+	portMutex.RLock()
+	for userID := range portfolios {
+		if !done(userID) {
+			if holdings := holdingsList(personalTransactionsLocked(userID), costBasisMethodLocked(userID)); len(holdings) > 0 {
+				pending[userID] = holdings
+			}
+		}
+	}
+	for _, f := range funds {
+		if key := fundSnapshotKey(f.ID); !done(key) {
+			if holdings := f.Holdings(); len(holdings) > 0 {
+				pending[key] = holdings
+			}
+		}
+	}
+	portMutex.RUnlock()
+	snapshotMutex.RUnlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	// Fetch each coin once for all portfolios
+	prices := make(map[string]float64)
+	symbols := map[string]bool{"bitcoin": true}
+	for _, holdings := range pending {
+		for _, h := range holdings {
+			symbols[h.Symbol] = true
+		}
+	}
+	for symbol := range symbols {
+		price, err := getCryptoPrice(symbol)
+		if err != nil {
+			log.Printf("Error getting price for %s snapshot: %v", symbol, err)
+			continue
+		}
+		prices[symbol] = price.Price
+	}
+
+	snapshotMutex.Lock()
+	defer snapshotMutex.Unlock()
+
+	var taken []string
+	for key, holdings := range pending {
+		snapshot, ok := takeSnapshot(holdings, prices, now)
+		if !ok {
+			continue
+		}
+		snapshots[key] = append(snapshots[key], snapshot)
+		taken = append(taken, key)
+	}
+
+	if len(taken) > 0 {
+		if err := writeSnapshots(taken); err != nil {
+			log.Printf("Error saving snapshots: %v", err)
+		}
+		log.Printf("Recorded %d portfolio snapshots", len(taken))
+	}
+}
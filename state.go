@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// botState is everything the bot persists, decoded from a single store
+// transaction so the subsystems always see a consistent point in time.
+type botState struct {
+	Portfolios    map[string]*Portfolio
+	Funds         map[string]*Fund
+	Snapshots     map[string][]PortfolioSnapshot
+	Alerts        map[string][]PriceAlert
+	PriceBots     map[string]priceBotRecord
+	GuildSettings map[string]*GuildSettings
+	Watchlists    map[string][]string
+}
+
+func decodeBucket[T any](tx StoreTx, bucket string, into map[string]T) error {
+	return tx.ForEach(bucket, func(key string, value []byte) error {
+		var v T
+		if err := json.Unmarshal(value, &v); err != nil {
+			return fmt.Errorf("error decoding %s/%s: %w", bucket, key, err)
+		}
+		into[key] = v
+		return nil
+	})
+}
+
+// readState decodes every bucket, failing on the first value that doesn't parse.
+func readState(tx StoreTx) (*botState, error) {
+	st := &botState{
+		Portfolios:    make(map[string]*Portfolio),
+		Funds:         make(map[string]*Fund),
+		Snapshots:     make(map[string][]PortfolioSnapshot),
+		Alerts:        make(map[string][]PriceAlert),
+		PriceBots:     make(map[string]priceBotRecord),
+		GuildSettings: make(map[string]*GuildSettings),
+		Watchlists:    make(map[string][]string),
+	}
+
+	decoders := []func() error{
+		func() error { return decodeBucket(tx, bucketPortfolios, st.Portfolios) },
+		func() error { return decodeBucket(tx, bucketFunds, st.Funds) },
+		func() error { return decodeBucket(tx, bucketSnapshots, st.Snapshots) },
+		func() error { return decodeBucket(tx, bucketAlerts, st.Alerts) },
+		func() error { return decodeBucket(tx, bucketPriceBots, st.PriceBots) },
+		func() error { return decodeBucket(tx, bucketGuildSettings, st.GuildSettings) },
+		func() error { return decodeBucket(tx, bucketWatchlists, st.Watchlists) },
+	}
+	for _, decode := range decoders {
+		if err := decode(); err != nil {
+			return nil, err
+		}
+	}
+
+	for guildID, settings := range st.GuildSettings {
+		settings.GuildID = guildID
+	}
+	return st, nil
+}
+
+// lockState takes every state lock. Code that holds more than one of them
+// must take them in this order, and always before starting a store
+// transaction.
+func lockState() {
+	snapshotMutex.Lock()
+	portMutex.Lock()
+	alertBot.AlertMutex.Lock()
+	settingsMutex.Lock()
+	watchlistMutex.Lock()
+}
+
+func unlockState() {
+	watchlistMutex.Unlock()
+	settingsMutex.Unlock()
+	alertBot.AlertMutex.Unlock()
+	portMutex.Unlock()
+	snapshotMutex.Unlock()
+}
+
+// applyLocked replaces the in-memory state. Price bots hold live sessions and
+// are started separately with restorePriceBots. The caller must hold lockState.
+func (st *botState) applyLocked() {
+	portfolios = st.Portfolios
+	funds = st.Funds
+	snapshots = st.Snapshots
+	alertBot.Alerts = st.Alerts
+	guildSettings = st.GuildSettings
+	watchlists = st.Watchlists
+}
+
+// loadState reads all bot state from the store into memory.
+func loadState() (*botState, error) {
+	var st *botState
+	err := store.View(func(tx StoreTx) error {
+		var err error
+		st, err = readState(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lockState()
+	st.applyLocked()
+	unlockState()
+	return st, nil
+}
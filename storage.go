@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// Store is a transactional key/value store with named buckets. Values are JSON.
+type Store interface {
+	// View runs fn in a read-only transaction.
+	View(fn func(tx StoreTx) error) error
+	// Update runs fn in a read-write transaction that is committed only if fn
+	// returns nil.
+	Update(fn func(tx StoreTx) error) error
+	Close() error
+}
+
+// StoreTx is a transaction on a Store. Missing buckets behave as empty.
+type StoreTx interface {
+	// Get returns nil if the key doesn't exist.
+	Get(bucket, key string) []byte
+	Put(bucket, key string, value []byte) error
+	Delete(bucket, key string) error
+	// ForEach visits the bucket's keys in sorted order.
+	ForEach(bucket string, fn func(key string, value []byte) error) error
+}
+
+const (
+	bucketMeta          = "meta"
+	bucketPortfolios    = "portfolios"
+	bucketFunds         = "funds"
+	bucketSnapshots     = "snapshots"
+	bucketAlerts        = "alerts"
+	bucketPriceBots     = "price_bots"
+	bucketGuildSettings = "guild_settings"
+	bucketWatchlists    = "watchlists"
+)
+
+// storeBuckets are the buckets holding bot state, everything except meta.
+var storeBuckets = []string{
+	bucketPortfolios,
+	bucketFunds,
+	bucketSnapshots,
+	bucketAlerts,
+	bucketPriceBots,
+	bucketGuildSettings,
+	bucketWatchlists,
+}
+
+const schemaVersionKey = "schema_version"
+
+var store Store
+
+// storeMigration upgrades the store from Version-1 to Version.
+type storeMigration struct {
+	Version     int
+	Description string
+	Apply       func(tx StoreTx) error
+}
+
+// Migrations run in order, each in its own transaction together with the
+// schema version bump. Never edit a released migration, add a new one.
+var storeMigrations = []storeMigration{
+	{Version: 1, Description: "import portfolios.json", Apply: importLegacyPortfolios},
+	{Version: 2, Description: "import portfolio_snapshots.json", Apply: importLegacySnapshots},
+}
+
+func latestSchemaVersion() int {
+	return storeMigrations[len(storeMigrations)-1].Version
+}
+
+func getJSON(tx StoreTx, bucket, key string, v interface{}) (bool, error) {
+	data := tx.Get(bucket, key)
+	if data == nil {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("error decoding %s/%s: %w", bucket, key, err)
+	}
+	return true, nil
+}
+
+func putJSON(tx StoreTx, bucket, key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error encoding %s/%s: %w", bucket, key, err)
+	}
+	return tx.Put(bucket, key, data)
+}
+
+func schemaVersion(tx StoreTx) (int, error) {
+	var version int
+	if _, err := getJSON(tx, bucketMeta, schemaVersionKey, &version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// migrateStore applies every migration newer than the store's schema version.
+func migrateStore(s Store) error {
+	var current int
+	err := s.View(func(tx StoreTx) error {
+		var err error
+		current, err = schemaVersion(tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if current > latestSchemaVersion() {
+		return fmt.Errorf("store schema version %d is newer than this build supports (%d)", current, latestSchemaVersion())
+	}
+
+	for _, m := range storeMigrations {
+		if m.Version <= current {
+			continue
+		}
+
+		err := s.Update(func(tx StoreTx) error {
+			if err := m.Apply(tx); err != nil {
+				return err
+			}
+			return putJSON(tx, bucketMeta, schemaVersionKey, m.Version)
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Description, err)
+		}
+		log.Printf("Migrated store to schema version %d: %s", m.Version, m.Description)
+	}
+	return nil
+}
+
+// openStore opens the backend selected by STORAGE_BACKEND ("bolt" or "json")
+// at STORAGE_PATH and brings its schema up to date.
+func openStore() error {
+	backend := os.Getenv("STORAGE_BACKEND")
+	if backend == "" {
+		backend = "bolt"
+	}
+	path := os.Getenv("STORAGE_PATH")
+
+	var (
+		s   Store
+		err error
+	)
+	switch backend {
+	case "bolt":
+		if path == "" {
+			path = "bot.db"
+		}
+		s, err = openBoltStore(path)
+	case "json":
+		if path == "" {
+			path = "bot-store.json"
+		}
+		s, err = openJSONStore(path)
+	default:
+		return fmt.Errorf("unknown STORAGE_BACKEND %q", backend)
+	}
+	if err != nil {
+		return fmt.Errorf("error opening %s store at %s: %w", backend, path, err)
+	}
+
+	if err := migrateStore(s); err != nil {
+		s.Close()
+		return err
+	}
+
+	store = s
+	return nil
+}
+
+// writeFileAtomic replaces path by writing a temporary file next to it and
+// renaming it, so readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+var errReadOnlyTx = errors.New("transaction is read-only")
+
+// jsonStore keeps every bucket in memory and rewrites a single JSON file on
+// each committed update. It's meant for tests and small deployments; with an
+// empty path nothing is written to disk.
+type jsonStore struct {
+	path    string
+	mu      sync.RWMutex
+	buckets map[string]map[string]json.RawMessage
+}
+
+func newMemoryStore() *jsonStore {
+	return &jsonStore{buckets: make(map[string]map[string]json.RawMessage)}
+}
+
+func openJSONStore(path string) (*jsonStore, error) {
+	s := newMemoryStore()
+	s.path = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.buckets); err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *jsonStore) View(fn func(tx StoreTx) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return fn(&jsonTx{store: s})
+}
+
+func (s *jsonStore) Update(fn func(tx StoreTx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := &jsonTx{store: s, writable: true, pending: make(map[string]map[string]json.RawMessage)}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if len(tx.pending) == 0 {
+		return nil
+	}
+
+	// Build the committed state without touching the current one until the
+	// file has been written
+	committed := make(map[string]map[string]json.RawMessage, len(s.buckets))
+	for name, bucket := range s.buckets {
+		committed[name] = bucket
+	}
+	for name, changes := range tx.pending {
+		bucket := make(map[string]json.RawMessage, len(committed[name])+len(changes))
+		for key, value := range committed[name] {
+			bucket[key] = value
+		}
+		for key, value := range changes {
+			if value == nil {
+				delete(bucket, key)
+			} else {
+				bucket[key] = value
+			}
+		}
+		committed[name] = bucket
+	}
+
+	if err := s.write(committed); err != nil {
+		return err
+	}
+	s.buckets = committed
+	return nil
+}
+
+func (s *jsonStore) write(buckets map[string]map[string]json.RawMessage) error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(buckets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data)
+}
+
+func (s *jsonStore) Close() error {
+	return nil
+}
+
+type jsonTx struct {
+	store    *jsonStore
+	writable bool
+	// Uncommitted changes, a nil value marks a deletion
+	pending map[string]map[string]json.RawMessage
+}
+
+func (t *jsonTx) Get(bucket, key string) []byte {
+	if changes, ok := t.pending[bucket]; ok {
+		if value, ok := changes[key]; ok {
+			if value == nil {
+				return nil
+			}
+			return append([]byte{}, value...)
+		}
+	}
+	if value, ok := t.store.buckets[bucket][key]; ok {
+		return append([]byte{}, value...)
+	}
+	return nil
+}
+
+func (t *jsonTx) Put(bucket, key string, value []byte) error {
+	if !t.writable {
+		return errReadOnlyTx
+	}
+	if !json.Valid(value) {
+		return fmt.Errorf("value for %s/%s isn't valid JSON", bucket, key)
+	}
+	t.change(bucket, key, append(json.RawMessage{}, value...))
+	return nil
+}
+
+func (t *jsonTx) Delete(bucket, key string) error {
+	if !t.writable {
+		return errReadOnlyTx
+	}
+	t.change(bucket, key, nil)
+	return nil
+}
+
+func (t *jsonTx) change(bucket, key string, value json.RawMessage) {
+	if t.pending[bucket] == nil {
+		t.pending[bucket] = make(map[string]json.RawMessage)
+	}
+	t.pending[bucket][key] = value
+}
+
+func (t *jsonTx) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	keys := make(map[string]bool)
+	for key := range t.store.buckets[bucket] {
+		keys[key] = true
+	}
+	for key := range t.pending[bucket] {
+		keys[key] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	for _, key := range sorted {
+		value := t.Get(bucket, key)
+		if value == nil {
+			continue
+		}
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
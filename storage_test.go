@@ -0,0 +1,285 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+
+	dir := t.TempDir()
+	bolt, err := openBoltStore(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatalf("opening bolt store: %v", err)
+	}
+	t.Cleanup(func() { bolt.Close() })
+
+	file, err := openJSONStore(filepath.Join(dir, "test.json"))
+	if err != nil {
+		t.Fatalf("opening json store: %v", err)
+	}
+
+	return map[string]Store{
+		"bolt":   bolt,
+		"json":   file,
+		"memory": newMemoryStore(),
+	}
+}
+
+func TestStoreCommitAndRollback(t *testing.T) {
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			err := s.Update(func(tx StoreTx) error {
+				return tx.Put(bucketPortfolios, "a", []byte(`{"user_id":"a"}`))
+			})
+			if err != nil {
+				t.Fatalf("update: %v", err)
+			}
+
+			failed := errors.New("failed")
+			err = s.Update(func(tx StoreTx) error {
+				if err := tx.Put(bucketPortfolios, "b", []byte(`{}`)); err != nil {
+					return err
+				}
+				if err := tx.Delete(bucketPortfolios, "a"); err != nil {
+					return err
+				}
+				return failed
+			})
+			if !errors.Is(err, failed) {
+				t.Fatalf("expected rollback error, got %v", err)
+			}
+
+			s.View(func(tx StoreTx) error {
+				if got := string(tx.Get(bucketPortfolios, "a")); got != `{"user_id":"a"}` {
+					t.Errorf("a = %q after rollback", got)
+				}
+				if got := tx.Get(bucketPortfolios, "b"); got != nil {
+					t.Errorf("b = %q after rollback, want nil", got)
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func TestStoreForEachSorted(t *testing.T) {
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			err := s.Update(func(tx StoreTx) error {
+				for _, key := range []string{"c", "a", "b"} {
+					if err := tx.Put(bucketFunds, key, []byte(`1`)); err != nil {
+						return err
+					}
+				}
+				return tx.Delete(bucketFunds, "b")
+			})
+			if err != nil {
+				t.Fatalf("update: %v", err)
+			}
+
+			var keys []string
+			s.View(func(tx StoreTx) error {
+				return tx.ForEach(bucketFunds, func(key string, value []byte) error {
+					keys = append(keys, key)
+					return nil
+				})
+			})
+			if want := []string{"a", "c"}; !reflect.DeepEqual(keys, want) {
+				t.Errorf("keys = %v, want %v", keys, want)
+			}
+		})
+	}
+}
+
+func TestStoreViewIsReadOnly(t *testing.T) {
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			err := s.View(func(tx StoreTx) error {
+				return tx.Put(bucketMeta, "key", []byte(`1`))
+			})
+			if err == nil {
+				t.Error("expected Put in a read-only transaction to fail")
+			}
+		})
+	}
+}
+
+func TestJSONStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	s, err := openJSONStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update(func(tx StoreTx) error {
+		return putJSON(tx, bucketMeta, schemaVersionKey, 3)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := openJSONStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reopened.View(func(tx StoreTx) error {
+		if version, _ := schemaVersion(tx); version != 3 {
+			t.Errorf("version = %d after reopening, want 3", version)
+		}
+		return nil
+	})
+}
+
+func TestMigrateStoreImportsLegacyFiles(t *testing.T) {
+	dir := t.TempDir()
+	oldSaveFile, oldSnapshotFile := saveFile, snapshotFile
+	saveFile = filepath.Join(dir, "portfolios.json")
+	snapshotFile = filepath.Join(dir, "portfolio_snapshots.json")
+	t.Cleanup(func() {
+		saveFile, snapshotFile = oldSaveFile, oldSnapshotFile
+		portMutex.Lock()
+		portfolios = make(map[string]*Portfolio)
+		funds = make(map[string]*Fund)
+		portMutex.Unlock()
+	})
+
+	legacy := `{"version":2,"portfolios":{"u1":{"user_id":"u1","transactions":[
+		{"id":"t1","side":"buy","symbol":"bitcoin","amount":1,"price":100}]}}}`
+	if err := os.WriteFile(saveFile, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(snapshotFile, []byte(`{"u1":[{"value":100}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newMemoryStore()
+	if err := migrateStore(s); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	s.View(func(tx StoreTx) error {
+		if version, _ := schemaVersion(tx); version != latestSchemaVersion() {
+			t.Errorf("version = %d, want %d", version, latestSchemaVersion())
+		}
+
+		var portfolio Portfolio
+		if ok, err := getJSON(tx, bucketPortfolios, "u1", &portfolio); !ok || err != nil {
+			t.Fatalf("portfolio not imported: %v", err)
+		}
+		if len(portfolio.Transactions) != 1 || portfolio.Transactions[0].ID != "t1" {
+			t.Errorf("unexpected transactions %+v", portfolio.Transactions)
+		}
+
+		var history []PortfolioSnapshot
+		if ok, _ := getJSON(tx, bucketSnapshots, "u1", &history); !ok || len(history) != 1 {
+			t.Errorf("snapshots not imported: %+v", history)
+		}
+		return nil
+	})
+
+	// A second run finds nothing to do, so a changed legacy file is ignored
+	os.WriteFile(saveFile, []byte(`not json`), 0644)
+	if err := migrateStore(s); err != nil {
+		t.Errorf("second migrate: %v", err)
+	}
+}
+
+func TestMigrateStoreRejectsNewerSchema(t *testing.T) {
+	s := newMemoryStore()
+	s.Update(func(tx StoreTx) error {
+		return putJSON(tx, bucketMeta, schemaVersionKey, latestSchemaVersion()+1)
+	})
+	if err := migrateStore(s); err == nil {
+		t.Error("expected an error for a newer schema version")
+	}
+}
+
+func TestBackupRestore(t *testing.T) {
+	oldStore, oldBackupDir := store, backupDir
+	store, backupDir = newMemoryStore(), t.TempDir()
+	t.Cleanup(func() {
+		store, backupDir = oldStore, oldBackupDir
+		lockState()
+		(&botState{
+			Portfolios:    make(map[string]*Portfolio),
+			Funds:         make(map[string]*Fund),
+			Snapshots:     make(map[string][]PortfolioSnapshot),
+			Alerts:        make(map[string][]PriceAlert),
+			GuildSettings: make(map[string]*GuildSettings),
+			Watchlists:    make(map[string][]string),
+		}).applyLocked()
+		unlockState()
+	})
+
+	store.Update(func(tx StoreTx) error {
+		putJSON(tx, bucketMeta, schemaVersionKey, latestSchemaVersion())
+		putJSON(tx, bucketAlerts, "bitcoin", []PriceAlert{{Symbol: "BTC", GeckoID: "bitcoin", UpperTarget: 100000}})
+		return putJSON(tx, bucketGuildSettings, "g1", &GuildSettings{FearGreedChannelID: "c1"})
+	})
+
+	name, err := createBackup()
+	if err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+
+	// Change the state after the backup, the restore must undo it
+	store.Update(func(tx StoreTx) error {
+		tx.Delete(bucketAlerts, "bitcoin")
+		return putJSON(tx, bucketPortfolios, "u1", &Portfolio{UserID: "u1"})
+	})
+
+	safety, st, err := restoreBackup(name)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if safety == name {
+		t.Error("expected a separate safety backup")
+	}
+	if len(st.Alerts["bitcoin"]) != 1 || st.GuildSettings["g1"].GuildID != "g1" {
+		t.Errorf("unexpected restored state %+v", st)
+	}
+
+	store.View(func(tx StoreTx) error {
+		if tx.Get(bucketPortfolios, "u1") != nil {
+			t.Error("portfolio added after the backup survived the restore")
+		}
+		if tx.Get(bucketAlerts, "bitcoin") == nil {
+			t.Error("alert wasn't restored")
+		}
+		return nil
+	})
+	if alertBot.Alerts["bitcoin"][0].UpperTarget != 100000 {
+		t.Error("in-memory alerts weren't replaced")
+	}
+}
+
+func TestReadBackupRejectsTampering(t *testing.T) {
+	oldStore, oldBackupDir := store, backupDir
+	store, backupDir = newMemoryStore(), t.TempDir()
+	t.Cleanup(func() { store, backupDir = oldStore, oldBackupDir })
+
+	store.Update(func(tx StoreTx) error {
+		putJSON(tx, bucketMeta, schemaVersionKey, latestSchemaVersion())
+		return putJSON(tx, bucketPortfolios, "u1", &Portfolio{UserID: "u1"})
+	})
+	name, err := createBackup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(backupDir, name)
+	data, _ := os.ReadFile(path)
+	tampered := []byte(strings.Replace(string(data), `"u1"`, `"u2"`, 1))
+	os.WriteFile(path, tampered, 0600)
+
+	if _, _, err := readBackup(name); err == nil {
+		t.Error("expected a checksum error for a modified backup")
+	}
+	if _, _, err := readBackup("../" + name); err == nil {
+		t.Error("expected an error for a path outside the backup directory")
+	}
+}
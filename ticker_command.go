@@ -0,0 +1,287 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// The ticker refreshes as often as the market cache, faster updates would
+// only repeat the same prices
+const tickerInterval = marketCacheTTL
+
+var defaultTickerCoins = []string{"bitcoin", "ethereum", "solana", "binancecoin", "ripple"}
+
+// TickerSettings is a live ticker message that is edited in place.
+type TickerSettings struct {
+	ChannelID string   `json:"channel_id"`
+	MessageID string   `json:"message_id"`
+	Coins     []string `json:"coins"` // CoinGecko IDs
+}
+
+func handleTickerCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return
+	}
+
+	switch options[0].Name {
+	case "setup":
+		handleTickerSetup(s, i, options[0].Options)
+	case "stop":
+		handleTickerStop(s, i)
+	}
+}
+
+func respondTicker(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// parseTickerCoins resolves a comma or space separated list of coins.
+func parseTickerCoins(input string) []string {
+	var coins []string
+	seen := make(map[string]bool)
+	for _, name := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' }) {
+		id := resolveCryptoInfo(name).GeckoID
+		if id != "" && !seen[id] {
+			seen[id] = true
+			coins = append(coins, id)
+		}
+	}
+	return coins
+}
+
+func handleTickerSetup(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	var channelID string
+	var coins []string
+	for _, opt := range options {
+		switch opt.Name {
+		case "channel":
+			channelID = opt.ChannelValue(s).ID
+		case "coins":
+			coins = parseTickerCoins(opt.StringValue())
+		}
+	}
+
+	// Default to the server watchlist, then to the largest coins
+	if len(coins) == 0 {
+		watchlistMutex.RLock()
+		coins = append(coins, watchlists[watchlistKey(watchlistScopeAll, i.GuildID)]...)
+		watchlistMutex.RUnlock()
+	}
+	if len(coins) == 0 {
+		coins = defaultTickerCoins
+	}
+	if len(coins) > maxWatchlistSize {
+		respondTicker(s, i, fmt.Sprintf("⚠️ The ticker can show at most %d coins", maxWatchlistSize))
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	markets, err := getCoinMarkets(coins)
+	if err != nil {
+		content := fmt.Sprintf("❌ Error fetching prices: %v", err)
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+		return
+	}
+
+	var unknown []string
+	for _, id := range coins {
+		if markets[id] == nil {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		content := fmt.Sprintf("❌ Unknown cryptocurrencies: %s", strings.Join(unknown, ", "))
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+		return
+	}
+
+	msg, err := s.ChannelMessageSendEmbed(channelID, createTickerEmbed(coins, markets))
+	if err != nil {
+		content := fmt.Sprintf("❌ Couldn't post in <#%s>: %v", channelID, err)
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+		return
+	}
+
+	var previous *TickerSettings
+	updateGuildSettings(i.GuildID, func(settings *GuildSettings) {
+		previous = settings.Ticker
+		settings.Ticker = &TickerSettings{
+			ChannelID: channelID,
+			MessageID: msg.ID,
+			Coins:     coins,
+		}
+	})
+	if previous != nil {
+		s.ChannelMessageDelete(previous.ChannelID, previous.MessageID)
+	}
+
+	content := fmt.Sprintf("✅ Live ticker posted in <#%s>, showing %d coins and updating every minute",
+		channelID, len(coins))
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &content,
+	})
+}
+
+func handleTickerStop(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var previous *TickerSettings
+	updateGuildSettings(i.GuildID, func(settings *GuildSettings) {
+		previous = settings.Ticker
+		settings.Ticker = nil
+	})
+
+	if previous == nil {
+		respondTicker(s, i, "There is no live ticker in this server")
+		return
+	}
+	s.ChannelMessageDelete(previous.ChannelID, previous.MessageID)
+	respondTicker(s, i, "✅ Live ticker stopped")
+}
+
+func createTickerEmbed(coins []string, markets map[string]*coinMarket) *discordgo.MessageEmbed {
+	embed := createWatchlistEmbed("📈 Live Ticker", coins, markets)
+	embed.Footer.Text = "Price · 24h change · 7d trend • Updates every minute"
+	return embed
+}
+
+// runTickerScheduler keeps every guild's ticker message up to date.
+func runTickerScheduler(s *discordgo.Session) {
+	ticker := time.NewTicker(tickerInterval)
+	defer ticker.Stop()
+
+	for {
+		updateTickers(s)
+		<-ticker.C
+	}
+}
+
+func updateTickers(s *discordgo.Session) {
+	type guildTicker struct {
+		guildID string
+		ticker  TickerSettings
+	}
+
+	var tickers []guildTicker
+	settingsMutex.RLock()
+	for guildID, settings := range guildSettings {
+		if settings.Ticker != nil {
+			tickers = append(tickers, guildTicker{guildID: guildID, ticker: *settings.Ticker})
+		}
+	}
+	settingsMutex.RUnlock()
+
+	if len(tickers) == 0 {
+		return
+	}
+
+	// Fetch every guild's coins in one batch
+	var ids []string
+	for _, t := range tickers {
+		ids = append(ids, t.ticker.Coins...)
+	}
+	markets, err := getCoinMarkets(ids)
+	if err != nil {
+		log.Printf("Error fetching ticker prices: %v", err)
+		return
+	}
+
+	for _, t := range tickers {
+		embed := createTickerEmbed(t.ticker.Coins, markets)
+		_, err := s.ChannelMessageEditEmbed(t.ticker.ChannelID, t.ticker.MessageID, embed)
+		if err == nil {
+			continue
+		}
+
+		var restErr *discordgo.RESTError
+		if !errors.As(err, &restErr) || restErr.Message == nil {
+			log.Printf("Error updating ticker in guild %s: %v", t.guildID, err)
+			continue
+		}
+
+		switch restErr.Message.Code {
+		case discordgo.ErrCodeUnknownMessage:
+			// Someone deleted the message, post a new one
+			msg, err := s.ChannelMessageSendEmbed(t.ticker.ChannelID, embed)
+			if err != nil {
+				log.Printf("Error reposting ticker in guild %s: %v", t.guildID, err)
+				continue
+			}
+			log.Printf("Reposted deleted ticker in guild %s", t.guildID)
+			setTickerMessage(t.guildID, t.ticker, msg.ID)
+		case discordgo.ErrCodeUnknownChannel, discordgo.ErrCodeMissingAccess:
+			log.Printf("Disabling ticker in guild %s: channel %s is gone or inaccessible", t.guildID, t.ticker.ChannelID)
+			setTickerMessage(t.guildID, t.ticker, "")
+		default:
+			log.Printf("Error updating ticker in guild %s: %v", t.guildID, err)
+		}
+	}
+}
+
+// setTickerMessage records a reposted ticker message, or disables the ticker
+// when messageID is empty. Nothing changes if the ticker was reconfigured in
+// the meantime.
+func setTickerMessage(guildID string, old TickerSettings, messageID string) {
+	updateGuildSettings(guildID, func(settings *GuildSettings) {
+		if settings.Ticker == nil || settings.Ticker.MessageID != old.MessageID {
+			return
+		}
+		if messageID == "" {
+			settings.Ticker = nil
+		} else {
+			settings.Ticker.MessageID = messageID
+		}
+	})
+}
+
+func tickerCommandOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "setup",
+			Description: "Post a live ticker message that updates in place",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "Channel to post the ticker in",
+					Required:     true,
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "coins",
+					Description: "Comma separated coins (default: the server watchlist)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "stop",
+			Description: "Stop and delete the live ticker",
+		},
+	}
+}
@@ -9,70 +9,186 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-func RestartAllBots() error {
-	botsMutex.Lock()
-	defer botsMutex.Unlock()
+// priceBotRecord is the saved assignment of a price bot. Tokens stay in the
+// environment; the bot is identified by its client ID.
+type priceBotRecord struct {
+	Symbol   string `json:"symbol"`
+	GuildID  string `json:"guild_id"`
+	ClientID string `json:"client_id"`
+}
 
-	log.Println("Starting bot restart process...")
+func clientIDForToken(token string) string {
+	for _, config := range botConfigs {
+		if config.Token == token {
+			return config.ClientID
+		}
+	}
+	return ""
+}
 
-	// Store existing bot configurations
-	botConfigs := make(map[string]PriceBot)
-	for key, bot := range priceBots {
-		botConfigs[key] = PriceBot{
-			Token:      bot.Token,
-			Symbol:     bot.Symbol,
-			GuildID:    bot.GuildID,
-			LastPrice:  bot.LastPrice,
-			LastUpdate: bot.LastUpdate,
+// savePriceBotLocked stores or deletes the assignment for symbol. The caller
+// must hold botsMutex.
+func savePriceBotLocked(symbol string) {
+	err := store.Update(func(tx StoreTx) error {
+		if bot, exists := priceBots[symbol]; exists {
+			return putJSON(tx, bucketPriceBots, symbol, priceBotRecord{
+				Symbol:   bot.Symbol,
+				GuildID:  bot.GuildID,
+				ClientID: bot.ClientID,
+			})
 		}
+		return tx.Delete(bucketPriceBots, symbol)
+	})
+	if err != nil {
+		log.Printf("Error saving price bot for %s: %v", symbol, err)
+	}
+}
+
+// openPriceBot connects a price bot session. A zero price leaves the nickname
+// to the next price update.
+func openPriceBot(token, symbol, guildID string, price float64) (*PriceBot, error) {
+	session, err := discordgo.New("Bot " + token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Enable required intents
+	session.Identify.Intents = discordgo.IntentsGuildMembers | discordgo.IntentsGuilds
+
+	bot := &PriceBot{
+		Token:     token,
+		ClientID:  clientIDForToken(token),
+		Symbol:    symbol,
+		GuildID:   guildID,
+		Session:   session,
+		LastPrice: price,
+	}
+	if price > 0 {
+		bot.LastUpdate = time.Now()
+	}
+
+	// Add ready handler for immediate price update
+	session.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
+		log.Printf("Price bot for %s is ready", strings.ToUpper(symbol))
+		if price > 0 {
+			updateBotNickname(bot, price)
+		}
+	})
+
+	if err := session.Open(); err != nil {
+		return nil, err
+	}
+	return bot, nil
+}
+
+// restorePriceBots replaces the running price bots with the saved assignments
+// and refills the token pool with the remaining tokens. Assignments whose
+// client ID is no longer configured are skipped.
+func restorePriceBots(records map[string]priceBotRecord) {
+	botsMutex.Lock()
+	defer botsMutex.Unlock()
+
+	for _, bot := range priceBots {
 		if err := bot.Session.Close(); err != nil {
 			log.Printf("Error closing session for bot %s: %v", bot.Symbol, err)
 		}
 	}
-
-	// Clear current bots
 	priceBots = make(map[string]*PriceBot)
 
-	// Recreate bots with saved configurations
-	for key, config := range botConfigs {
-		session, err := discordgo.New("Bot " + config.Token)
+	tokenPool = nil
+	tokens := make(map[string]string)
+	for _, config := range botConfigs {
+		if config.Token == "" {
+			continue
+		}
+		tokenPool = append(tokenPool, config.Token)
+		tokens[config.ClientID] = config.Token
+	}
+
+	for symbol, record := range records {
+		token, ok := tokens[record.ClientID]
+		if !ok {
+			log.Printf("Skipping price bot for %s: client ID %s is not configured", symbol, record.ClientID)
+			continue
+		}
+		for idx, pooled := range tokenPool {
+			if pooled == token {
+				tokenPool = append(tokenPool[:idx], tokenPool[idx+1:]...)
+				break
+			}
+		}
+
+		bot, err := openPriceBot(token, symbol, record.GuildID, 0)
 		if err != nil {
-			log.Printf("Error creating new session for %s: %v", config.Symbol, err)
+			log.Printf("Error restoring price bot for %s: %v", symbol, err)
+			tokenPool = append(tokenPool, token)
 			continue
 		}
+		priceBots[symbol] = bot
+
+		// Allow some time between bot starts to prevent rate limiting
+		time.Sleep(time.Second * 2)
+	}
+
+	log.Printf("Restored %d price bots, %d tokens available", len(priceBots), len(tokenPool))
+}
+
+// RestartGuildBots reconnects the price bots added in a guild.
+func RestartGuildBots(guildID string) (int, error) {
+	botsMutex.Lock()
+	defer botsMutex.Unlock()
+
+	log.Printf("Starting bot restart process for guild %s...", guildID)
 
-		// Initialize the new bot session
-		if err := session.Open(); err != nil {
-			log.Printf("Error opening new session for %s: %v", config.Symbol, err)
-			session.Close()
+	// Store the running bots' settings before closing them
+	running := make(map[string]*PriceBot)
+	for key, bot := range priceBots {
+		if bot.GuildID != guildID {
 			continue
 		}
+		running[key] = bot
+		if err := bot.Session.Close(); err != nil {
+			log.Printf("Error closing session for bot %s: %v", bot.Symbol, err)
+		}
+	}
 
-		priceBots[key] = &PriceBot{
-			Session:    session,
-			Token:      config.Token,
-			Symbol:     config.Symbol,
-			GuildID:    config.GuildID,
-			LastPrice:  config.LastPrice,
-			LastUpdate: config.LastUpdate,
+	// Reopen each bot with its saved settings
+	restarted := 0
+	for key, previous := range running {
+		bot, err := openPriceBot(previous.Token, previous.Symbol, previous.GuildID, previous.LastPrice)
+		if err != nil {
+			log.Printf("Error reopening price bot for %s: %v", previous.Symbol, err)
+			delete(priceBots, key)
+			savePriceBotLocked(key)
+			tokenPool = append(tokenPool, previous.Token)
+			continue
 		}
+		bot.LastUpdate = previous.LastUpdate
+		priceBots[key] = bot
+		restarted++
 
 		// Allow some time between bot restarts to prevent rate limiting
 		time.Sleep(time.Second * 2)
 	}
 
-	log.Printf("Bot restart completed. %d bots restarted", len(priceBots))
-	return nil
+	log.Printf("Bot restart completed. %d bots restarted", restarted)
+	return restarted, nil
 }
 
-// ClearAllBots removes all bots and returns their tokens to the pool
-func ClearAllBots() error {
+// ClearGuildBots removes the price bots added in a guild and returns their
+// tokens to the pool
+func ClearGuildBots(guildID string) (int, error) {
 	botsMutex.Lock()
 	defer botsMutex.Unlock()
 
-	log.Println("Starting bot cleanup process...")
+	log.Printf("Starting bot cleanup process for guild %s...", guildID)
 
-	for _, bot := range priceBots {
+	var symbols []string
+	for symbol, bot := range priceBots {
+		if bot.GuildID != guildID {
+			continue
+		}
+		symbols = append(symbols, symbol)
 		if bot.Session != nil {
 			// Attempt to reset nickname before closing
 			err := bot.Session.GuildMemberNickname(bot.GuildID, "@me", "")
@@ -90,11 +206,13 @@ func ClearAllBots() error {
 		tokenPool = append(tokenPool, bot.Token)
 	}
 
-	// Clear the price bots map
-	priceBots = make(map[string]*PriceBot)
+	for _, symbol := range symbols {
+		delete(priceBots, symbol)
+		savePriceBotLocked(symbol)
+	}
 
-	log.Println("All bots have been cleared and tokens returned to pool")
-	return nil
+	log.Printf("%d bots have been cleared and tokens returned to pool", len(symbols))
+	return len(symbols), nil
 }
 
 func handleRestartCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
@@ -102,13 +220,13 @@ func handleRestartCommand(s *discordgo.Session, i *discordgo.InteractionCreate)
 		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
 	})
 
-	err := RestartAllBots()
+	restarted, err := RestartGuildBots(i.GuildID)
 
 	var response string
 	if err != nil {
 		response = fmt.Sprintf("❌ Error restarting bots: %v", err)
 	} else {
-		response = "✅ Successfully restarted all price bots"
+		response = fmt.Sprintf("✅ Successfully restarted %d price bots", restarted)
 	}
 
 	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
@@ -121,13 +239,13 @@ func handleClearCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
 	})
 
-	err := ClearAllBots()
+	cleared, err := ClearGuildBots(i.GuildID)
 
 	var response string
 	if err != nil {
 		response = fmt.Sprintf("❌ Error clearing bots: %v", err)
 	} else {
-		response = "✅ Successfully cleared all price bots"
+		response = fmt.Sprintf("✅ Successfully cleared %d price bots", cleared)
 	}
 
 	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
@@ -188,37 +306,8 @@ func handleAddCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	token := tokenPool[0]
 	tokenPool = tokenPool[1:]
 
-	session, err := discordgo.New("Bot " + token)
+	bot, err := openPriceBot(token, symbol, i.GuildID, price.Price)
 	if err != nil {
-		tokenPool = append(tokenPool, token)
-		content := "❌ Error creating bot connection"
-		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: &content,
-		})
-		return
-	}
-
-	// Enable required intents
-	session.Identify.Intents = discordgo.IntentsGuildMembers | discordgo.IntentsGuilds
-
-	// Create bot instance
-	bot := &PriceBot{
-		Token:      token,
-		Symbol:     symbol,
-		GuildID:    i.GuildID,
-		Session:    session,
-		LastPrice:  price.Price,
-		LastUpdate: time.Now(),
-	}
-
-	// Add ready handler for immediate price update
-	session.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
-		log.Printf("Price bot for %s is ready", strings.ToUpper(symbol))
-		updateBotNickname(bot, price.Price)
-	})
-
-	// Open connection
-	if err := session.Open(); err != nil {
 		tokenPool = append(tokenPool, token)
 		content := fmt.Sprintf("❌ Error connecting bot: %v", err)
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
@@ -231,13 +320,14 @@ func handleAddCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	nickname := formatNickname(symbol, price.Price)
 
 	// Retry logic for setting nickname
-	err = retryNicknameUpdate(session, i.GuildID, nickname)
+	err = retryNicknameUpdate(bot.Session, i.GuildID, nickname)
 	if err != nil {
 		log.Printf("Warning: Initial nickname set failed for %s: %v", symbol, err)
 	}
 
 	// Store bot in map
 	priceBots[symbol] = bot
+	savePriceBotLocked(symbol)
 
 	// Create success response
 	embed := &discordgo.MessageEmbed{
@@ -281,10 +371,11 @@ func handleRemoveCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	symbol := strings.ToLower(options[0].StringValue())
 
 	botsMutex.Lock()
-	if bot, exists := priceBots[symbol]; exists {
+	if bot, exists := priceBots[symbol]; exists && bot.GuildID == i.GuildID {
 		bot.Session.Close()
 		tokenPool = append(tokenPool, bot.Token)
 		delete(priceBots, symbol)
+		savePriceBotLocked(symbol)
 		botsMutex.Unlock()
 
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	maxWatchlistSize   = 25
+	watchlistSparkLen  = 14 // Points per sparkline, two per day of the 7 day history
+	watchlistScopeMine = "mine"
+	watchlistScopeAll  = "server"
+)
+
+var (
+	// Watchlists keyed by watchlistKey, holding CoinGecko IDs in the order they were added
+	watchlists     = make(map[string][]string)
+	watchlistMutex sync.RWMutex
+)
+
+func watchlistKey(scope, id string) string {
+	if scope == watchlistScopeAll {
+		return "guild:" + id
+	}
+	return "user:" + id
+}
+
+// writeWatchlistLocked stores one watchlist. The caller must hold watchlistMutex.
+func writeWatchlistLocked(key string) error {
+	return store.Update(func(tx StoreTx) error {
+		if coins := watchlists[key]; len(coins) > 0 {
+			return putJSON(tx, bucketWatchlists, key, coins)
+		}
+		return tx.Delete(bucketWatchlists, key)
+	})
+}
+
+func respondWatch(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func handleWatchCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return
+	}
+
+	var coin string
+	scope := watchlistScopeMine
+	for _, opt := range options[0].Options {
+		switch opt.Name {
+		case "crypto":
+			coin = opt.StringValue()
+		case "scope":
+			scope = opt.StringValue()
+		}
+	}
+
+	key := watchlistKey(scope, i.Member.User.ID)
+	listName := "your watchlist"
+	if scope == watchlistScopeAll {
+		if !isManager(i) {
+			respondWatch(s, i, fmt.Sprintf("⚠️ You need %s to change the server watchlist", permissionManager.requirement()))
+			return
+		}
+		key = watchlistKey(scope, i.GuildID)
+		listName = "the server watchlist"
+	}
+
+	info := resolveCryptoInfo(coin)
+
+	switch options[0].Name {
+	case "add":
+		markets, err := getCoinMarkets([]string{info.GeckoID})
+		if err != nil || markets[info.GeckoID] == nil {
+			respondWatch(s, i, fmt.Sprintf("❌ Cryptocurrency '%s' not found.\n"+
+				"Please use autocomplete to select a valid cryptocurrency.", coin))
+			return
+		}
+
+		watchlistMutex.Lock()
+		content := addToWatchlistLocked(key, info, listName)
+		watchlistMutex.Unlock()
+		respondWatch(s, i, content)
+
+	case "remove":
+		watchlistMutex.Lock()
+		content := removeFromWatchlistLocked(key, info, listName)
+		watchlistMutex.Unlock()
+		respondWatch(s, i, content)
+	}
+}
+
+// addToWatchlistLocked adds the coin to the watchlist under key and returns the
+// reply. The caller must hold watchlistMutex.
+func addToWatchlistLocked(key string, info CryptoInfo, listName string) string {
+	for _, id := range watchlists[key] {
+		if id == info.GeckoID {
+			return fmt.Sprintf("%s is already on %s", info.Symbol, listName)
+		}
+	}
+	if len(watchlists[key]) >= maxWatchlistSize {
+		return fmt.Sprintf("⚠️ A watchlist can hold at most %d coins", maxWatchlistSize)
+	}
+
+	watchlists[key] = append(watchlists[key], info.GeckoID)
+	if err := writeWatchlistLocked(key); err != nil {
+		log.Printf("Error saving watchlist %s: %v", key, err)
+	}
+	return fmt.Sprintf("👀 Added %s to %s", info.Symbol, listName)
+}
+
+// removeFromWatchlistLocked removes the coin from the watchlist under key and
+// returns the reply. The caller must hold watchlistMutex.
+func removeFromWatchlistLocked(key string, info CryptoInfo, listName string) string {
+	coins := watchlists[key]
+	for idx, id := range coins {
+		if id == info.GeckoID {
+			watchlists[key] = append(coins[:idx:idx], coins[idx+1:]...)
+			if len(watchlists[key]) == 0 {
+				delete(watchlists, key)
+			}
+			if err := writeWatchlistLocked(key); err != nil {
+				log.Printf("Error saving watchlist %s: %v", key, err)
+			}
+			return fmt.Sprintf("Removed %s from %s", info.Symbol, listName)
+		}
+	}
+	return fmt.Sprintf("%s isn't on %s", info.Symbol, listName)
+}
+
+func handleWatchlistCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	scope := ""
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "scope" {
+			scope = opt.StringValue()
+		}
+	}
+
+	watchlistMutex.RLock()
+	mine := append([]string{}, watchlists[watchlistKey(watchlistScopeMine, i.Member.User.ID)]...)
+	server := append([]string{}, watchlists[watchlistKey(watchlistScopeAll, i.GuildID)]...)
+	watchlistMutex.RUnlock()
+
+	// Without a scope show the user's own list, or the server's when it's empty
+	coins, title := mine, fmt.Sprintf("👀 %s's Watchlist", i.Member.User.Username)
+	if scope == watchlistScopeAll || (scope == "" && len(mine) == 0) {
+		coins, title = server, "👀 Server Watchlist"
+	}
+
+	if len(coins) == 0 {
+		respondWatch(s, i, "The watchlist is empty. Add coins with `/watch add`.")
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+
+	markets, err := getCoinMarkets(coins)
+	if err != nil {
+		content := fmt.Sprintf("❌ Error fetching prices: %v", err)
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+		return
+	}
+
+	embed := createWatchlistEmbed(title, coins, markets)
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+}
+
+// createWatchlistEmbed lays the coins out as aligned rows in a code block so
+// the whole list fits in one compact embed.
+func createWatchlistEmbed(title string, coins []string, markets map[string]*coinMarket) *discordgo.MessageEmbed {
+	var (
+		lines       []string
+		totalChange float64
+		counted     int
+	)
+	for _, id := range coins {
+		symbol := resolveCryptoInfo(id).Symbol
+		market := markets[id]
+		if market == nil {
+			lines = append(lines, fmt.Sprintf("%-6s %12s", symbol, "n/a"))
+			continue
+		}
+		if market.Symbol != "" {
+			symbol = strings.ToUpper(market.Symbol)
+		}
+
+		line := fmt.Sprintf("%-6s %12s %+7.2f%%", symbol, formatUSD(market.CurrentPrice), market.Change24h)
+		if spark := sparkline(downsample(market.Sparkline.Price, watchlistSparkLen)); spark != "" {
+			line += " " + spark
+		}
+		lines = append(lines, line)
+		totalChange += market.Change24h
+		counted++
+	}
+
+	color := 0x808080
+	if counted > 0 {
+		color = getColorForChange(totalChange / float64(counted))
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       title,
+		Description: "```\n" + strings.Join(lines, "\n") + "\n```",
+		Color:       color,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Price · 24h change · 7d trend",
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+}
+
+func watchCommandOptions() []*discordgo.ApplicationCommandOption {
+	scope := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "scope",
+		Description: "Your own watchlist or the server's (default: yours)",
+		Required:    false,
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{Name: "Mine", Value: watchlistScopeMine},
+			{Name: "Server", Value: watchlistScopeAll},
+		},
+	}
+	crypto := &discordgo.ApplicationCommandOption{
+		Type:         discordgo.ApplicationCommandOptionString,
+		Name:         "crypto",
+		Description:  "Cryptocurrency (e.g., bitcoin)",
+		Required:     true,
+		Autocomplete: true,
+	}
+
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "add",
+			Description: "Add a coin to a watchlist",
+			Options:     []*discordgo.ApplicationCommandOption{crypto, scope},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "remove",
+			Description: "Remove a coin from a watchlist",
+			Options:     []*discordgo.ApplicationCommandOption{crypto, scope},
+		},
+	}
+}
+
+func watchlistCommandOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "scope",
+			Description: "Which watchlist to show (default: yours, or the server's if yours is empty)",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "Mine", Value: watchlistScopeMine},
+				{Name: "Server", Value: watchlistScopeAll},
+			},
+		},
+	}
+}